@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+// checkGeneratedCode generate code in memory and print unified diff against
+// existing output file to standard output if they are different.
+func checkGeneratedCode(outputFilePath string, fanoutInstance *httproutegen.FanoutInstance, param *commandParameters) (upToDate bool, err error) {
+	codeGenInst, err := httproutegen.NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, param.diagnostics)
+	if nil != err {
+		return
+	}
+	applyCodeGenerateParameters(codeGenInst, param)
+	var expectContent bytes.Buffer
+	if err = codeGenInst.GenerateTo(&expectContent); nil != err {
+		return
+	}
+	return checkOutputContent(outputFilePath, expectContent.Bytes())
+}
+
+// checkRuntimeCode print unified diff against existing shared runtime file
+// to standard output if it is not up to date.
+func checkRuntimeCode(runtimeFilePath, packageName string) (upToDate bool, err error) {
+	var expectContent bytes.Buffer
+	if err = httproutegen.GenerateRuntimeTo(&expectContent, packageName); nil != err {
+		return
+	}
+	return checkOutputContent(runtimeFilePath, expectContent.Bytes())
+}
+
+// checkOutputContent print unified diff between existing output file and
+// expected content to standard output if they are different.
+func checkOutputContent(outputFilePath string, expectContent []byte) (upToDate bool, err error) {
+	currentContent, err := ioutil.ReadFile(outputFilePath)
+	if nil != err {
+		if !os.IsNotExist(err) {
+			return
+		}
+		currentContent, err = nil, nil
+	}
+	if bytes.Equal(currentContent, expectContent) {
+		return true, nil
+	}
+	_, err = os.Stdout.WriteString(makeUnifiedDiff(outputFilePath, outputFilePath+" (generated)", string(currentContent), string(expectContent)))
+	return false, err
+}
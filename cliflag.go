@@ -3,7 +3,10 @@ package main
 import (
 	"errors"
 	"flag"
+	"log"
 	"path/filepath"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
 )
 
 // ErrInputFileRequired indicates input file path is missing.
@@ -12,32 +15,202 @@ var ErrInputFileRequired = errors.New("Input file is required")
 // ErrOutputFileRequired indicates output file path is missing.
 var ErrOutputFileRequired = errors.New("Output file is required")
 
-func parseCommandParam() (inputFilePath, outputFilePath, packageName, receiverName, handlerTypeName, routeMethodName, genNamePrefix string, dumpFanoutContent bool, err error) {
-	flag.StringVar(&inputFilePath, "in", "", "path to input file")
-	flag.StringVar(&outputFilePath, "out", "", "path to output file")
-	flag.StringVar(&packageName, "package", "", "package name")
-	flag.StringVar(&receiverName, "receiver", "h", "name of receiver variable")
-	flag.StringVar(&handlerTypeName, "type", "myHandler", "name of handler type")
-	flag.StringVar(&routeMethodName, "methodName", "routeRequest", "name of routing method function")
-	flag.StringVar(&genNamePrefix, "prefix", "", "prefix to generated type or constant name")
-	flag.BoolVar(&dumpFanoutContent, "dumpFanoutContent", false, "dump fanout data structure content to logging output")
+// ErrUnknownBackend indicates code generation backend is neither "code" nor "program".
+var ErrUnknownBackend = errors.New("Backend must be \"code\" or \"program\"")
+
+// ErrTestOutRequiresHandlerInterface indicates test output is requested without handler interface.
+var ErrTestOutRequiresHandlerInterface = errors.New("Test output requires -handlerInterface")
+
+// Code generation backends.
+const (
+	backendRoutingCode  = "code"
+	backendRouteProgram = "program"
+)
+
+type commandParameters struct {
+	InputFilePath     string
+	OutputFilePath    string
+	PackageName       string
+	ReceiverName      string
+	HandlerTypeName   string
+	RouteMethodName   string
+	GenNamePrefix     string
+	RuntimeFileName   string
+	Backend           string
+	SwitchWidth       int
+	BinarySearchWidth int
+	SplitAreas        bool
+	SplitLineLimit    int
+	CountHits         bool
+	TimeHandlers      bool
+	ObserverName      string
+	HandlerInterface  bool
+	NoPrefixDigest64  bool
+	DumpFanoutContent bool
+	OpenAPIFilePath   string
+	ImportOpenAPI     bool
+	DocumentFilePath  string
+	DOTFilePath       string
+	TestFilePath      string
+	FuzzTest          bool
+	Lint              bool
+	CheckHandlers     bool
+	CheckOutput       bool
+	Strict            bool
+
+	explicitFlags map[string]bool
+	diagnostics   *httproutegen.CountingDiagnostics
+}
+
+// applyGeneratorOptions take generator options from route configuration
+// for the flags which are not given in command line.
+func (p *commandParameters) applyGeneratorOptions(opts *httproutegen.GeneratorOptions) {
+	if nil == opts {
+		return
+	}
+	apply := func(flagName string, target *string, value string) {
+		if ("" != value) && !p.explicitFlags[flagName] {
+			*target = value
+		}
+	}
+	apply("package", &p.PackageName, opts.PackageName)
+	apply("receiver", &p.ReceiverName, opts.ReceiverName)
+	apply("type", &p.HandlerTypeName, opts.HandlerTypeName)
+	apply("methodName", &p.RouteMethodName, opts.RouteMethodName)
+	apply("prefix", &p.GenNamePrefix, opts.NamePrefix)
+	apply("runtime", &p.RuntimeFileName, opts.RuntimeFileName)
+	apply("backend", &p.Backend, opts.Backend)
+	apply("observer", &p.ObserverName, opts.ObserverMethodName)
+	applyInt := func(flagName string, target *int, value int) {
+		if (0 != value) && !p.explicitFlags[flagName] {
+			*target = value
+		}
+	}
+	applyInt("switchWidth", &p.SwitchWidth, opts.SwitchMinWidth)
+	applyInt("binarySearchWidth", &p.BinarySearchWidth, opts.BinarySearchMinWidth)
+	applyInt("splitLines", &p.SplitLineLimit, opts.SplitLineLimit)
+	applyBool := func(flagName string, target *bool, value bool) {
+		if value && !p.explicitFlags[flagName] {
+			*target = value
+		}
+	}
+	applyBool("noDigest64", &p.NoPrefixDigest64, opts.NoPrefixDigest64)
+	applyBool("splitAreas", &p.SplitAreas, opts.SplitAreas)
+	applyBool("countHits", &p.CountHits, opts.CountRouteHits)
+	applyBool("timeHandlers", &p.TimeHandlers, opts.TimeRouteHandlers)
+	applyBool("handlerInterface", &p.HandlerInterface, opts.UseHandlerInterface)
+}
+
+// checkBackend verify code generation backend taken from flags and generator options.
+func (p *commandParameters) checkBackend() error {
+	if (backendRoutingCode != p.Backend) && (backendRouteProgram != p.Backend) {
+		return ErrUnknownBackend
+	}
+	return nil
+}
+
+// checkTestOutput verify route code taken from flags and generator options can be tested.
+func (p *commandParameters) checkTestOutput() error {
+	if ("" != p.TestFilePath) && !p.HandlerInterface {
+		return ErrTestOutRequiresHandlerInterface
+	}
+	return nil
+}
+
+// runtimeFilePath return path of shared runtime file in the folder of output file,
+// or empty string if shared runtime is not enabled.
+func (p *commandParameters) runtimeFilePath() string {
+	if ("" == p.RuntimeFileName) || filepath.IsAbs(p.RuntimeFileName) {
+		return p.RuntimeFileName
+	}
+	return filepath.Join(filepath.Dir(p.OutputFilePath), p.RuntimeFileName)
+}
+
+// failOnStrictDiagnostics stop with failure in strict mode if any warning or error is reported.
+func (p *commandParameters) failOnStrictDiagnostics() {
+	if !p.Strict || ((0 == p.diagnostics.WarningCount) && (0 == p.diagnostics.ErrorCount)) {
+		return
+	}
+	log.Fatalf("ERR: strict mode: %d warning(s) and %d error(s) reported", p.diagnostics.WarningCount, p.diagnostics.ErrorCount)
+}
+
+func absFilePath(p *string) (err error) {
+	if "" == *p {
+		return nil
+	}
+	*p, err = filepath.Abs(*p)
+	return
+}
+
+func parseCommandParam() (param *commandParameters, err error) {
+	var p commandParameters
+	flag.StringVar(&p.InputFilePath, "in", "", "path to input file")
+	flag.StringVar(&p.OutputFilePath, "out", "", "path to output file, or HTTP address to serve fanout content if starts with colon (HTTP is not available with routers)")
+	flag.StringVar(&p.PackageName, "package", "", "package name")
+	flag.StringVar(&p.ReceiverName, "receiver", "h", "name of receiver variable")
+	flag.StringVar(&p.HandlerTypeName, "type", "myHandler", "name of handler type")
+	flag.StringVar(&p.RouteMethodName, "methodName", "routeRequest", "name of routing method function")
+	flag.StringVar(&p.GenNamePrefix, "prefix", "", "prefix to generated type or constant name")
+	flag.StringVar(&p.RuntimeFileName, "runtime", "", "name of shared runtime helper file in the folder of output file, built-in helpers are written there instead of output file")
+	flag.StringVar(&p.Backend, "backend", backendRoutingCode, "code generation backend: \"code\" for nested routing code, \"program\" for byte program with fixed interpreter")
+	flag.IntVar(&p.SwitchWidth, "switchWidth", 0, "number of prefix or fuzzy matching branches from which switch statement is generated (0 for default)")
+	flag.IntVar(&p.BinarySearchWidth, "binarySearchWidth", 0, "number of prefix or fuzzy matching branches from which explicit binary search is generated (0 for default)")
+	flag.BoolVar(&p.SplitAreas, "splitAreas", false, "generate routing code of each area as its own method of handler type")
+	flag.IntVar(&p.SplitLineLimit, "splitLines", 0, "generate routing code of subtree over given lines as its own method of handler type (0 for no limit)")
+	flag.BoolVar(&p.CountHits, "countHits", false, "count routed requests of each route miss and route target with package-level counters")
+	flag.BoolVar(&p.TimeHandlers, "timeHandlers", false, "sum up time spent in handler of each route target, implies -countHits")
+	flag.StringVar(&p.ObserverName, "observer", "", "name of handler type method invoked with request, route ident and route template before handler")
+	flag.BoolVar(&p.HandlerInterface, "handlerInterface", false, "declare routing logic on dispatch type over an interface of handler methods, required by -testOut")
+	flag.BoolVar(&p.NoPrefixDigest64, "noDigest64", false, "compare at most 4 bytes in one prefix matching step instead of merging steps into 8 bytes digest")
+	flag.BoolVar(&p.DumpFanoutContent, "dumpFanoutContent", false, "dump fanout data structure content to logging output")
+	flag.StringVar(&p.OpenAPIFilePath, "openapi", "", "path to OpenAPI document output (JSON if ends with .json, YAML otherwise), not available with routers")
+	flag.BoolVar(&p.ImportOpenAPI, "importOpenAPI", false, "read OpenAPI document from input file and write route configuration YAML into output file")
+	flag.StringVar(&p.DocumentFilePath, "doc", "", "path to Markdown route reference document output, not available with routers")
+	flag.StringVar(&p.DOTFilePath, "dot", "", "path to Graphviz DOT output of fanout decision tree, not available with routers")
+	flag.StringVar(&p.TestFilePath, "testOut", "", "path to generated test file of route method (requires -out and -handlerInterface), not available with routers")
+	flag.BoolVar(&p.FuzzTest, "fuzz", false, "include fuzz target of route method in generated test file (requires Go 1.18)")
+	flag.BoolVar(&p.Lint, "lint", false, "check route configuration for ambiguous, misrouted and unreachable routes, fail on any finding")
+	flag.BoolVar(&p.CheckHandlers, "checkHandlers", false, "type-check package of output file and verify handler method signatures instead of generating code (requires -out)")
+	flag.BoolVar(&p.CheckOutput, "check", false, "verify output file is up to date instead of writing it, print unified diff and fail on difference (requires -out)")
+	flag.BoolVar(&p.Strict, "strict", false, "fail when any warning or error diagnostic is reported while loading configuration, expanding fanout or generating code")
 	flag.Parse()
-	if "" == inputFilePath {
+	p.diagnostics = &httproutegen.CountingDiagnostics{}
+	p.explicitFlags = make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		p.explicitFlags[f.Name] = true
+	})
+	if "" == p.InputFilePath {
 		err = ErrInputFileRequired
 		return
 	}
-	if inputFilePath, err = filepath.Abs(inputFilePath); nil != err {
+	if err = absFilePath(&p.InputFilePath); nil != err {
+		return
+	}
+	if err = absFilePath(&p.OpenAPIFilePath); nil != err {
 		return
 	}
-	if "" == outputFilePath {
-		err = ErrOutputFileRequired
+	if err = absFilePath(&p.DocumentFilePath); nil != err {
 		return
 	}
-	if ':' != outputFilePath[0] {
-		if outputFilePath, err = filepath.Abs(outputFilePath); nil != err {
+	if err = absFilePath(&p.DOTFilePath); nil != err {
+		return
+	}
+	if err = absFilePath(&p.TestFilePath); nil != err {
+		return
+	}
+	if "" == p.OutputFilePath {
+		if ("" != p.TestFilePath) || p.CheckHandlers || p.CheckOutput {
+			return nil, ErrOutputFileRequired
+		}
+		if ("" == p.OpenAPIFilePath) && ("" == p.DocumentFilePath) && ("" == p.DOTFilePath) && !p.Lint {
+			err = ErrOutputFileRequired
+		}
+		return &p, err
+	}
+	if ':' != p.OutputFilePath[0] {
+		if err = absFilePath(&p.OutputFilePath); nil != err {
 			return
 		}
 	}
-	err = nil
-	return
+	return &p, nil
 }
@@ -0,0 +1,557 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+const diffTestHandlerTypeName = "diffTestHandler"
+
+var requestMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+const driverCode = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+)
+
+type ` + diffTestHandlerTypeName + ` struct {
+	handlerName string
+	pathOffset  int
+	params      []string
+}
+
+func (h *` + diffTestHandlerTypeName + `) record(handlerName string, pathOffset int, params ...interface{}) {
+	h.handlerName = handlerName
+	h.pathOffset = pathOffset
+	h.params = nil
+	for _, v := range params {
+		h.params = append(h.params, fmt.Sprintf("%T:%v", v, v))
+	}
+}
+
+type requestCase struct {
+	Method string
+	Path   string
+}
+
+type routeOutcome struct {
+	RouteIdent  string
+	HandlerName string
+	PathOffset  int
+	Parameters  []string
+	Failed      bool
+}
+
+func main() {
+	buf, err := ioutil.ReadFile(os.Args[1])
+	if nil != err {
+		panic(err)
+	}
+	var cases []requestCase
+	if err = json.Unmarshal(buf, &cases); nil != err {
+		panic(err)
+	}
+	outcomes := make([]routeOutcome, 0, len(cases))
+	for _, c := range cases {
+		h := &` + diffTestHandlerTypeName + `{}
+		req := &http.Request{
+			Method: c.Method,
+			URL:    &url.URL{Path: c.Path},
+		}
+		ident, err := h.routeRequest(httptest.NewRecorder(), req)
+		outcomes = append(outcomes, routeOutcome{
+			RouteIdent:  ident.String(),
+			HandlerName: h.handlerName,
+			PathOffset:  h.pathOffset,
+			Parameters:  h.params,
+			Failed:      nil != err,
+		})
+	}
+	if err = json.NewEncoder(os.Stdout).Encode(outcomes); nil != err {
+		panic(err)
+	}
+}
+`
+
+type requestCase struct {
+	Method string
+	Path   string
+
+	target     *httproutegen.RouteTarget
+	parameters []*httproutegen.RouteParameterValue
+	ambiguous  bool
+}
+
+type routeOutcome struct {
+	RouteIdent  string
+	HandlerName string
+	PathOffset  int
+	Parameters  []string
+	Failed      bool
+}
+
+func formatParameters(params []*httproutegen.RouteParameterValue) (result []string) {
+	for _, p := range params {
+		result = append(result, fmt.Sprintf("%T:%v", p.Value, p.Value))
+	}
+	return
+}
+
+func (o *routeOutcome) String() string {
+	return fmt.Sprintf("ident=%s, handler=%s, offset=%d, params=%v, failed=%v", o.RouteIdent, o.HandlerName, o.PathOffset, o.Parameters, o.Failed)
+}
+
+func (o *routeOutcome) equal(other *routeOutcome) bool {
+	if (o.RouteIdent != other.RouteIdent) || (o.HandlerName != other.HandlerName) || (o.Failed != other.Failed) {
+		return false
+	}
+	if "" == o.HandlerName {
+		return true
+	}
+	return (o.PathOffset == other.PathOffset) && (strings.Join(o.Parameters, "\n") == strings.Join(other.Parameters, "\n"))
+}
+
+func makeHandlerStubCode(targets []*httproutegen.RouteTarget) string {
+	stubCodes := make(map[string]string)
+	for _, target := range targets {
+		var paramDecls, paramNames []string
+		for _, param := range target.Parameters {
+			paramDecls = append(paramDecls, ", "+param.Name+" "+param.Type)
+			paramNames = append(paramNames, ", "+param.Name)
+		}
+		for _, handlerName := range target.HandlerNames() {
+			stubCodes[handlerName] = "func (h *" + diffTestHandlerTypeName + ") " + handlerName +
+				"(w http.ResponseWriter, req *http.Request, pathOffset int" + strings.Join(paramDecls, "") + ") {\n" +
+				"\th.record(" + fmt.Sprintf("%q", handlerName) + ", pathOffset" + strings.Join(paramNames, "") + ")\n" +
+				"}\n\n"
+		}
+	}
+	var handlerNames []string
+	for handlerName := range stubCodes {
+		handlerNames = append(handlerNames, handlerName)
+	}
+	sort.Strings(handlerNames)
+	result := "package main\n\nimport (\n\t\"net/http\"\n)\n\n"
+	for _, handlerName := range handlerNames {
+		result += stubCodes[handlerName]
+	}
+	return result
+}
+
+// generatorOptions is code generation options under test.
+type generatorOptions struct {
+	switchMinWidth       int
+	binarySearchMinWidth int
+	useRouteProgram      bool
+	splitAreas           bool
+	splitLineLimit       int
+	countRouteHits       bool
+	timeRouteHandlers    bool
+	noPrefixDigest64     bool
+}
+
+func (opts *generatorOptions) apply(codeGenInst *httproutegen.CodeGenerateInstance) {
+	codeGenInst.SwitchMinWidth = opts.switchMinWidth
+	codeGenInst.BinarySearchMinWidth = opts.binarySearchMinWidth
+	codeGenInst.UseRouteProgram = opts.useRouteProgram
+	codeGenInst.SplitAreas = opts.splitAreas
+	codeGenInst.SplitLineLimit = opts.splitLineLimit
+	codeGenInst.CountRouteHits = opts.countRouteHits
+	codeGenInst.TimeRouteHandlers = opts.timeRouteHandlers
+}
+
+func generateRouteModule(moduleDirPath string, fanoutInstance *httproutegen.FanoutInstance, targets []*httproutegen.RouteTarget, opts *generatorOptions) (err error) {
+	if err = ioutil.WriteFile(filepath.Join(moduleDirPath, "go.mod"), []byte("module routedifftest\n\ngo 1.12\n"), 0644); nil != err {
+		return
+	}
+	if err = ioutil.WriteFile(filepath.Join(moduleDirPath, "driver.go"), []byte(driverCode), 0644); nil != err {
+		return
+	}
+	if err = ioutil.WriteFile(filepath.Join(moduleDirPath, "handler.go"), []byte(makeHandlerStubCode(targets)), 0644); nil != err {
+		return
+	}
+	codeGenInst, err := httproutegen.NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, nil)
+	if nil != err {
+		return
+	}
+	codeGenInst.PackageName = "main"
+	codeGenInst.ReceiverName = "h"
+	codeGenInst.HandlerTypeName = diffTestHandlerTypeName
+	codeGenInst.RouteMethodName = "routeRequest"
+	opts.apply(codeGenInst)
+	return codeGenInst.GenerateFile(filepath.Join(moduleDirPath, "handler_route.go"))
+}
+
+func runGeneratedRouter(moduleDirPath string, cases []*requestCase) (outcomes []*routeOutcome, err error) {
+	buf, err := json.Marshal(cases)
+	if nil != err {
+		return
+	}
+	casesFilePath := filepath.Join(moduleDirPath, "cases.json")
+	if err = ioutil.WriteFile(casesFilePath, buf, 0644); nil != err {
+		return
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", "run", ".", casesFilePath)
+	cmd.Dir = moduleDirPath
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); nil != err {
+		return
+	}
+	err = json.Unmarshal(stdout.Bytes(), &outcomes)
+	return
+}
+
+func runInterpreter(router *httproutegen.Router, c *requestCase) *routeOutcome {
+	result, err := router.Route(c.Method, c.Path)
+	outcome := &routeOutcome{
+		Failed: nil != err,
+	}
+	if nil != result {
+		outcome.RouteIdent = result.RouteIdent
+		outcome.HandlerName = result.HandlerName
+		if "" != result.HandlerName {
+			outcome.PathOffset = result.PathOffset
+			outcome.Parameters = formatParameters(result.Parameters)
+		}
+	}
+	return outcome
+}
+
+// expectReference return outcome expected by pattern of matched route target.
+// The path offset is not known by the pattern and is copied from actual outcome.
+func expectReference(c *requestCase, actual *routeOutcome) *routeOutcome {
+	handlerName := c.target.HandlerNameOfMethod(c.Method)
+	if "" == handlerName {
+		return &routeOutcome{
+			RouteIdent: "RouteMethodNotAllowed",
+		}
+	}
+	hnd := []rune(handlerName)
+	return &routeOutcome{
+		RouteIdent:  "RouteTo" + strings.ToUpper(string(hnd[0])) + string(hnd[1:]),
+		HandlerName: handlerName,
+		PathOffset:  actual.PathOffset,
+		Parameters:  formatParameters(c.parameters),
+	}
+}
+
+// isStrictMatchTarget check if every component of given target is strict-match.
+func isStrictMatchTarget(target *httproutegen.RouteTarget) bool {
+	for _, routeEntry := range append(target.Ancestors, target.Route) {
+		if ("" != routeEntry.Component) && !routeEntry.StrictMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// findStrictMatchTarget return the strict-match target routed to given handler for given method.
+// Nil is returned if any target routed to the handler is not strict-match.
+func findStrictMatchTarget(targets []*httproutegen.RouteTarget, method, handlerName string) (result *httproutegen.RouteTarget) {
+	for _, target := range targets {
+		if target.HandlerNameOfMethod(method) != handlerName {
+			continue
+		}
+		if !isStrictMatchTarget(target) || (nil != result) {
+			return nil
+		}
+		result = target
+	}
+	return
+}
+
+// matchStrictLiterals check if given path fully match literal bytes of target.
+// Sequences take zero or more allowed bytes as the generated code does, and
+// parameter values are left to the comparison with route interpreter.
+func matchStrictLiterals(target *httproutegen.RouteTarget, reqPath string) bool {
+	offset := strings.IndexByte(reqPath, '/') + 1
+	if offset == 0 {
+		return false
+	}
+	for _, sym := range target.Symbols {
+		switch sym.Type {
+		case httproutegen.SymbolTypeByte:
+			if (offset >= len(reqPath)) || (reqPath[offset] != sym.ByteValue) {
+				return false
+			}
+			offset++
+		case httproutegen.SymbolTypeSequence:
+			for (offset < len(reqPath)) && sym.SequenceValue.ByteMap.HasByte(reqPath[offset]) {
+				offset++
+			}
+		}
+	}
+	return offset == len(reqPath)
+}
+
+// expectStrictMatch return outcome expected for a path which no target fully matches
+// but is routed to strict-match target. Every byte of strict-match target is compared
+// so the path must match literals of the target up to path offset.
+func expectStrictMatch(c *requestCase, target *httproutegen.RouteTarget, actual *routeOutcome) *routeOutcome {
+	if (actual.PathOffset < 0) || (actual.PathOffset > len(c.Path)) || !matchStrictLiterals(target, c.Path[:actual.PathOffset]) {
+		return &routeOutcome{
+			RouteIdent: "RouteNone",
+		}
+	}
+	return actual
+}
+
+func mutatePath(rnd *rand.Rand, p string, alphabet []byte) string {
+	b := []byte(p)
+	switch op := rnd.Intn(4); {
+	case len(b) == 0:
+		return string(alphabet[rnd.Intn(len(alphabet))])
+	case op == 0:
+		b = b[:rnd.Intn(len(b))]
+	case op == 1:
+		b[rnd.Intn(len(b))] = alphabet[rnd.Intn(len(alphabet))]
+	case op == 2:
+		idx := rnd.Intn(len(b) + 1)
+		b = append(b[:idx], append([]byte{alphabet[rnd.Intn(len(alphabet))]}, b[idx:]...)...)
+	default:
+		idx := rnd.Intn(len(b))
+		b = append(b[:idx], b[idx+1:]...)
+	}
+	return string(b)
+}
+
+func makeAlphabet(targets []*httproutegen.RouteTarget) (alphabet []byte) {
+	seen := make(map[byte]bool)
+	for _, ch := range []byte("/-_.09azAZ{}") {
+		seen[ch] = true
+	}
+	for _, target := range targets {
+		for _, ch := range []byte(target.Template) {
+			seen[ch] = true
+		}
+	}
+	for ch := range seen {
+		alphabet = append(alphabet, ch)
+	}
+	sort.Slice(alphabet, func(i, j int) bool { return alphabet[i] < alphabet[j] })
+	return
+}
+
+func loadCorpus(corpusFilePath string) (paths []string, err error) {
+	fp, err := os.Open(corpusFilePath)
+	if nil != err {
+		return
+	}
+	defer fp.Close()
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); "" != line {
+			paths = append(paths, line)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// attachReference find the only target fully matching path of given case.
+func attachReference(c *requestCase, targets []*httproutegen.RouteTarget) {
+	for _, target := range targets {
+		params, ok := target.MatchPath(c.Path)
+		if !ok {
+			continue
+		}
+		if nil != c.target {
+			c.target = nil
+			c.parameters = nil
+			c.ambiguous = true
+			return
+		}
+		c.target = target
+		c.parameters = params
+	}
+}
+
+func makeRequestCases(targets []*httproutegen.RouteTarget, corpusPaths []string, randomCount int, seed int64) (cases []*requestCase) {
+	var samplePaths []string
+	for _, target := range targets {
+		for _, samplePath := range target.MakeSamplePaths() {
+			samplePaths = append(samplePaths, samplePath.Path)
+			for _, method := range requestMethods {
+				cases = append(cases, &requestCase{
+					Method:     method,
+					Path:       samplePath.Path,
+					target:     target,
+					parameters: samplePath.Parameters,
+				})
+			}
+		}
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	alphabet := makeAlphabet(targets)
+	otherPaths := append([]string{}, corpusPaths...)
+	for idx := 0; (idx < randomCount) && (len(samplePaths) > 0); idx++ {
+		p := samplePaths[rnd.Intn(len(samplePaths))]
+		for n := rnd.Intn(3); n >= 0; n-- {
+			p = mutatePath(rnd, p, alphabet)
+		}
+		otherPaths = append(otherPaths, p)
+	}
+	for _, p := range otherPaths {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+			c := &requestCase{
+				Method: method,
+				Path:   p,
+			}
+			attachReference(c, targets)
+			cases = append(cases, c)
+		}
+	}
+	return
+}
+
+func runDiffTest(fanoutInstance *httproutegen.FanoutInstance, targets []*httproutegen.RouteTarget, cases []*requestCase, keepModule bool, opts *generatorOptions) (mismatchCount int, err error) {
+	moduleDirPath, err := ioutil.TempDir("", "route-difftest-")
+	if nil != err {
+		return
+	}
+	if keepModule {
+		log.Printf("INFO: temporary module: %v", moduleDirPath)
+	} else {
+		defer os.RemoveAll(moduleDirPath)
+	}
+	if err = generateRouteModule(moduleDirPath, fanoutInstance, targets, opts); nil != err {
+		return
+	}
+	generatedOutcomes, err := runGeneratedRouter(moduleDirPath, cases)
+	if nil != err {
+		return
+	}
+	router := httproutegen.NewRouter(fanoutInstance)
+	interpreterMismatch, referenceMismatch, referenceChecked := 0, 0, 0
+	ambiguousCount, notRoutedCount, strictChecked, prefixRoutedCount := 0, 0, 0, 0
+	for idx, c := range cases {
+		generated := generatedOutcomes[idx]
+		if interpreted := runInterpreter(router, c); !generated.equal(interpreted) {
+			interpreterMismatch++
+			fmt.Printf("INTERPRETER MISMATCH: %s %q\n  generated:   %s\n  interpreter: %s\n", c.Method, c.Path, generated, interpreted)
+		}
+		if nil == c.target {
+			if c.ambiguous {
+				ambiguousCount++
+				continue
+			}
+			if "" == generated.HandlerName {
+				notRoutedCount++
+				continue
+			}
+			strictTarget := findStrictMatchTarget(targets, c.Method, generated.HandlerName)
+			if nil == strictTarget {
+				prefixRoutedCount++
+				continue
+			}
+			strictChecked++
+			if expected := expectStrictMatch(c, strictTarget, generated); !generated.equal(expected) {
+				referenceMismatch++
+				fmt.Printf("STRICT MATCH MISMATCH: %s %q (%s)\n  generated: %s\n  expected:  %s\n", c.Method, c.Path, strictTarget.Pattern, generated, expected)
+			}
+			continue
+		}
+		referenceChecked++
+		if expected := expectReference(c, generated); !generated.equal(expected) {
+			referenceMismatch++
+			fmt.Printf("REFERENCE MISMATCH: %s %q (%s)\n  generated: %s\n  expected:  %s\n", c.Method, c.Path, c.target.Pattern, generated, expected)
+		}
+	}
+	log.Printf("INFO: %d cases, %d checked with reference, %d interpreter mismatch, %d reference mismatch.",
+		len(cases), referenceChecked, interpreterMismatch, referenceMismatch)
+	log.Printf("INFO: cases matching no single target: %d ambiguous, %d not routed, %d routed to strict-match target (checked), %d routed to other target (not checked).",
+		ambiguousCount, notRoutedCount, strictChecked, prefixRoutedCount)
+	return interpreterMismatch + referenceMismatch, nil
+}
+
+func main() {
+	var inputFilePath, corpusFilePath string
+	var randomCount int
+	var seed int64
+	var keepModule bool
+	var opts generatorOptions
+	flag.StringVar(&inputFilePath, "in", "", "path to route configuration")
+	flag.StringVar(&corpusFilePath, "corpus", "", "path to file of additional request paths, one path per line")
+	flag.IntVar(&randomCount, "random", 1000, "number of random paths mutated from sample paths")
+	flag.Int64Var(&seed, "seed", 1, "seed of random path generator")
+	flag.BoolVar(&keepModule, "keep", false, "keep temporary module of generated code")
+	flag.IntVar(&opts.switchMinWidth, "switchWidth", 0, "number of branches from which switch statement is generated (0 for default)")
+	flag.IntVar(&opts.binarySearchMinWidth, "binarySearchWidth", 0, "number of branches from which binary search is generated (0 for default)")
+	flag.BoolVar(&opts.useRouteProgram, "program", false, "generate route program with fixed interpreter instead of nested routing code")
+	flag.BoolVar(&opts.splitAreas, "splitAreas", false, "generate subtree of each area as its own route function")
+	flag.IntVar(&opts.splitLineLimit, "splitLines", 0, "generate subtree over given lines as its own route function (0 for no limit)")
+	flag.BoolVar(&opts.countRouteHits, "countHits", false, "count hits of route misses and route targets in generated code")
+	flag.BoolVar(&opts.timeRouteHandlers, "timeHandlers", false, "sum up time spent in handlers in generated code")
+	flag.BoolVar(&opts.noPrefixDigest64, "noDigest64", false, "compare at most 4 bytes in one prefix matching step")
+	flag.Parse()
+	if "" == inputFilePath {
+		log.Fatal("ERR: require route configuration (-in)")
+		return
+	}
+	rootRouteEntry, err := httproutegen.LoadYAML(inputFilePath)
+	if nil != err {
+		log.Fatalf("ERR: cannot load route configuration [%s]: %v", inputFilePath, err)
+		return
+	}
+	targets, err := httproutegen.CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		log.Fatalf("ERR: cannot collect route targets: %v", err)
+		return
+	}
+	fanoutInstance, err := httproutegen.MakeFanoutInstance(rootRouteEntry)
+	if nil != err {
+		log.Fatalf("ERR: cannot create fanout instance from root route entry: %v", err)
+		return
+	}
+	fanoutInstance.DisablePrefixDigest64 = opts.noPrefixDigest64
+	if err = fanoutInstance.ExpandFanout(); nil != err {
+		log.Fatalf("ERR: cannot expand fanout instance: %v", err)
+		return
+	}
+	var corpusPaths []string
+	if "" != corpusFilePath {
+		if corpusPaths, err = loadCorpus(corpusFilePath); nil != err {
+			log.Fatalf("ERR: cannot load corpus [%s]: %v", corpusFilePath, err)
+			return
+		}
+	}
+	cases := makeRequestCases(targets, corpusPaths, randomCount, seed)
+	mismatchCount, err := runDiffTest(fanoutInstance, targets, cases, keepModule, &opts)
+	if nil != err {
+		log.Fatalf("ERR: cannot run differential test: %v", err)
+		return
+	}
+	if mismatchCount > 0 {
+		os.Exit(1)
+	}
+}
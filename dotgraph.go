@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+func writeFanoutForkDOT(dotFilePath string, rootFanoutFork *httproutegen.FanoutFork) (err error) {
+	fp, err := os.Create(dotFilePath)
+	if nil != err {
+		return
+	}
+	defer fp.Close()
+	return httproutegen.WriteFanoutForkDOT(fp, rootFanoutFork)
+}
@@ -4,7 +4,6 @@ go 1.12
 
 require (
 	github.com/kr/pretty v0.1.0 // indirect
-	github.com/yinyin/go-run-gofmt v1.0.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.2.2
 )
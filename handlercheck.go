@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+func isIdentByte(ch byte) bool {
+	return (ch == '_') || ((ch >= '0') && (ch <= '9')) || ((ch >= 'A') && (ch <= 'Z')) || ((ch >= 'a') && (ch <= 'z'))
+}
+
+// findLineOfName return 1-based number of the first line containing given name as a whole word, or 0 if not found.
+func findLineOfName(lines []string, name string) int {
+	for idx, line := range lines {
+		for offset := 0; offset < len(line); {
+			i := strings.Index(line[offset:], name)
+			if i < 0 {
+				break
+			}
+			begin := offset + i
+			end := begin + len(name)
+			if ((begin == 0) || !isIdentByte(line[begin-1])) && ((end == len(line)) || !isIdentByte(line[end])) {
+				return idx + 1
+			}
+			offset = end
+		}
+	}
+	return 0
+}
+
+// checkHandlerSignatures log handler methods which do not fit generated route method and return count of mismatches.
+// Mismatches are reported against the line of route configuration where the handler is named.
+func checkHandlerSignatures(inputFilePath string, rootRouteEntry *httproutegen.RouteEntry, outputFilePath, handlerTypeName string, excludeFilePaths ...string) (mismatchCount int, err error) {
+	targets, err := httproutegen.CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		return
+	}
+	excludeFileNames := []string{filepath.Base(outputFilePath)}
+	for _, p := range excludeFilePaths {
+		if "" != p {
+			excludeFileNames = append(excludeFileNames, filepath.Base(p))
+		}
+	}
+	handlerType, err := httproutegen.LoadHandlerType(filepath.Dir(outputFilePath), handlerTypeName, excludeFileNames)
+	if nil != err {
+		return
+	}
+	buf, err := ioutil.ReadFile(inputFilePath)
+	if nil != err {
+		return
+	}
+	lines := strings.Split(string(buf), "\n")
+	mismatches := httproutegen.CheckHandlerSignatures(handlerType, targets)
+	for _, mismatch := range mismatches {
+		log.Printf("%s:%d: %v", inputFilePath, findLineOfName(lines, mismatch.HandlerName), mismatch)
+	}
+	return len(mismatches), nil
+}
@@ -2,7 +2,6 @@ package httproutegen
 
 import (
 	"fmt"
-	"log"
 )
 
 func computeBitMapParam(b byte) (mapIndex int, bitOffset uint) {
@@ -14,7 +13,8 @@ func computeBitMapParam(b byte) (mapIndex int, bitOffset uint) {
 	return
 }
 
-// ByteMapper record how bytes map to scalar data type for handler arguments
+// ByteMapper record how bytes map to scalar data type for handler arguments.
+// Bytes > 127 are not supported and ignored.
 type ByteMapper struct {
 	bits [2]uint64
 }
@@ -29,7 +29,6 @@ func (m *ByteMapper) MarshalJSON() ([]byte, error) {
 // HasByte check if given byte is enabled in this mapper.
 func (m *ByteMapper) HasByte(b byte) bool {
 	if b > 127 {
-		log.Printf("WARN: register byte > 127: %v", b)
 		return false
 	}
 	bIndex, offset := computeBitMapParam(b)
@@ -38,7 +37,6 @@ func (m *ByteMapper) HasByte(b byte) bool {
 
 func (m *ByteMapper) enableByte(b byte) {
 	if b > 127 {
-		log.Printf("WARN: register byte > 127: %v", b)
 		return
 	}
 	bIndex, offset := computeBitMapParam(b)
@@ -47,7 +45,6 @@ func (m *ByteMapper) enableByte(b byte) {
 
 func (m *ByteMapper) enableByteRange(b0, b1 byte) {
 	if (b0 > 127) || (b1 > 127) {
-		log.Printf("WARN: register byte range > 127: %v - %v", b0, b1)
 		return
 	}
 	if b1 < b0 {
@@ -64,7 +61,6 @@ func (m *ByteMapper) enablePrintables() {
 
 func (m *ByteMapper) disableByte(b byte) {
 	if b > 127 {
-		log.Printf("WARN: register byte > 127: %v", b)
 		return
 	}
 	bIndex, offset := computeBitMapParam(b)
@@ -73,7 +69,6 @@ func (m *ByteMapper) disableByte(b byte) {
 
 func (m *ByteMapper) disableByteRange(b0, b1 byte) {
 	if (b0 > 127) || (b1 > 127) {
-		log.Printf("WARN: register byte range > 127: %v - %v", b0, b1)
 		return
 	}
 	if b1 < b0 {
@@ -141,3 +136,93 @@ func (m *ByteMapper) SetByteMap(c []byte, stopByte byte) int {
 func (m *ByteMapper) ByteMap() (uint64, uint64) {
 	return m.bits[0], m.bits[1]
 }
+
+// FirstCommonByte return the lowest byte enabled in both this and given mapper.
+func (m *ByteMapper) FirstCommonByte(other *ByteMapper) (b byte, found bool) {
+	for idx := 0; idx < 2; idx++ {
+		if common := m.bits[idx] & other.bits[idx]; 0 != common {
+			for offset := uint(0); offset < 64; offset++ {
+				if 0 != (common & (1 << offset)) {
+					return byte(idx*64) + byte(offset), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// ByteRange represent a continuous range of enabled bytes.
+type ByteRange struct {
+	From byte
+	To   byte
+}
+
+// ByteRanges return enabled bytes as continuous ranges.
+func (m *ByteMapper) ByteRanges() (result []ByteRange) {
+	inRange := false
+	for b := 0; b < 128; b++ {
+		if m.HasByte(byte(b)) {
+			if inRange {
+				result[len(result)-1].To = byte(b)
+			} else {
+				result = append(result, ByteRange{From: byte(b), To: byte(b)})
+				inRange = true
+			}
+		} else {
+			inRange = false
+		}
+	}
+	return
+}
+
+func regexpCharClassByte(b byte) string {
+	switch {
+	case (b < 0x20) || (b > 0x7E):
+		return fmt.Sprintf("\\x%02X", b)
+	case (b == '\\') || (b == ']') || (b == '[') || (b == '^') || (b == '-'):
+		return "\\" + string(b)
+	}
+	return string(b)
+}
+
+// RegexpCharClass return enabled bytes in form of regular expression character class.
+func (m *ByteMapper) RegexpCharClass() string {
+	result := "["
+	for _, r := range m.ByteRanges() {
+		result += regexpCharClassByte(r.From)
+		if r.To == r.From {
+			continue
+		}
+		if r.To > r.From+1 {
+			result += "-"
+		}
+		result += regexpCharClassByte(r.To)
+	}
+	return result + "]"
+}
+
+func ruleTextByte(b byte) string {
+	switch b {
+	case '\\', '-', ',', '^', '{', '}':
+		return "\\" + string(b)
+	}
+	return string(b)
+}
+
+// RuleText return enabled bytes in form of byte map rule for SetByteMap.
+func (m *ByteMapper) RuleText() (result string) {
+	for _, r := range m.ByteRanges() {
+		if (r.From < 0x20) || (r.To > 0x7E) {
+			continue
+		}
+		result += ruleTextByte(r.From)
+		if r.To == r.From {
+			continue
+		}
+		if r.To > r.From+1 {
+			result += "-"
+		}
+		result += ruleTextByte(r.To)
+	}
+	return
+}
@@ -25,6 +25,95 @@ func makeCodeConstRouteIdent(routePrefix string, coveredAreaRouteIdents []string
 		"\n"
 }
 
+func makeCodeMethodRouteIdentString(routePrefix string, routeIdentNameCases string) string {
+	return "// String return name of route identifier.\n" +
+		"func (ident " + (routePrefix + "RouteIdent") + ") String() string {\n" +
+		"\tswitch ident {\n" +
+		(routeIdentNameCases) + "\n" +
+		"\t}\n" +
+		"\treturn \"" + (routePrefix + "RouteIdent") + "(\" + strconv.FormatInt(int64(ident), 10) + \")\"\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeMethodRouteIdentTemplate(routePrefix string, routeIdentTemplateCases string) string {
+	return "// Template return path template of routed target.\n" +
+		"// Empty string will be returned for identifiers which are not route target.\n" +
+		"func (ident " + (routePrefix + "RouteIdent") + ") Template() string {\n" +
+		"\tswitch ident {\n" +
+		(routeIdentTemplateCases) + "\n" +
+		"\t}\n" +
+		"\treturn \"\"\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeVarRouteDescriptors(routePrefix string, routeDescriptorElements string) string {
+	return "// " + (routePrefix + "RouteDescriptor") + " describe one route target for runtime introspection.\n" +
+		"type " + (routePrefix + "RouteDescriptor") + " struct {\n" +
+		"\tIdent          " + (routePrefix + "RouteIdent") + "\n" +
+		"\tAreaName       string\n" +
+		"\tMethods        []string\n" +
+		"\tHandlerName    string\n" +
+		"\tPattern        string\n" +
+		"\tTemplate       string\n" +
+		"\tParameterNames []string\n" +
+		"\tParameterTypes []string\n" +
+		"}\n" +
+		"\n" +
+		"// " + (routePrefix + "RouteDescriptors") + " is the list of route targets.\n" +
+		"var " + (routePrefix + "RouteDescriptors") + " = []" + (routePrefix + "RouteDescriptor") + "{\n" +
+		(routeDescriptorElements) + "\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeRouteHitCounters(routePrefix string, helperSuffix string, identCount int) string {
+	return "var " + ("routeHitCounters" + helperSuffix) + " [" + (strconv.FormatInt(int64(identCount), 10)) + "]uint64\n" +
+		"\n" +
+		"// " + ("countRouteHit" + helperSuffix) + " increase counter of given route miss or route target ident.\n" +
+		"func " + ("countRouteHit" + helperSuffix) + "(ident " + (routePrefix + "RouteIdent") + ") " + (routePrefix + "RouteIdent") + " {\n" +
+		"\tif ident > " + (routePrefix + "RouteError") + " {\n" +
+		"\t\tatomic.AddUint64(&" + ("routeHitCounters" + helperSuffix) + "[ident], 1)\n" +
+		"\t}\n" +
+		"\treturn ident\n" +
+		"}\n" +
+		"\n" +
+		"// " + (routePrefix + "RouteHitCounts") + " return snapshot of hit counts of route misses and route targets by ident name.\n" +
+		"func " + (routePrefix + "RouteHitCounts") + "() map[string]uint64 {\n" +
+		"\tresult := make(map[string]uint64)\n" +
+		"\tfor idx := range " + ("routeHitCounters" + helperSuffix) + " {\n" +
+		"\t\tif ident := " + (routePrefix + "RouteIdent") + "(idx); (ident > " + (routePrefix + "RouteError") + ") && (ident != " + (routePrefix + "RouteSuccess") + ") {\n" +
+		"\t\t\tresult[ident.String()] = atomic.LoadUint64(&" + ("routeHitCounters" + helperSuffix) + "[idx])\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn result\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeRouteHandlerTimers(routePrefix string, helperSuffix string, identCount int) string {
+	return "var " + ("routeHandlerNanoseconds" + helperSuffix) + " [" + (strconv.FormatInt(int64(identCount), 10)) + "]int64\n" +
+		"\n" +
+		"// " + ("timeRouteHandler" + helperSuffix) + " add time spent since given start time to handler of given route target ident.\n" +
+		"func " + ("timeRouteHandler" + helperSuffix) + "(ident " + (routePrefix + "RouteIdent") + ", startAt time.Time) " + (routePrefix + "RouteIdent") + " {\n" +
+		"\tatomic.AddInt64(&" + ("routeHandlerNanoseconds" + helperSuffix) + "[ident], int64(time.Since(startAt)))\n" +
+		"\treturn " + ("countRouteHit" + helperSuffix) + "(ident)\n" +
+		"}\n" +
+		"\n" +
+		"// " + (routePrefix + "RouteHandlerDurations") + " return snapshot of total time spent in handlers by route target ident name.\n" +
+		"func " + (routePrefix + "RouteHandlerDurations") + "() map[string]time.Duration {\n" +
+		"\tresult := make(map[string]time.Duration)\n" +
+		"\tfor idx := range " + ("routeHandlerNanoseconds" + helperSuffix) + " {\n" +
+		"\t\tif ident := " + (routePrefix + "RouteIdent") + "(idx); ident > " + (routePrefix + "RouteSuccess") + " {\n" +
+		"\t\t\tresult[ident.String()] = time.Duration(atomic.LoadInt64(&" + ("routeHandlerNanoseconds" + helperSuffix) + "[idx]))\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn result\n" +
+		"}\n" +
+		"\n"
+}
+
 func makeCodeMethodRouteEnterance(routePrefix string, receiverName string, handlerTypeName string, routeMethodName string, routingLogicCode string) string {
 	return "func (" + (receiverName) + " *" + (handlerTypeName) + ") " + (routeMethodName) + "(w http.ResponseWriter, req *http.Request) (" + (routePrefix + "RouteIdent") + ", error) {\n" +
 		"\treqPath := req.URL.Path\n" +
@@ -48,26 +137,64 @@ func makeCodeMethodRouteEnterance(routePrefix string, receiverName string, handl
 		"\n"
 }
 
-const codeErrFragmentSmallerThanExpect = "var errFragmentSmallerThanExpect = errors.New(\"remaining path fragment smaller than expect\")\n" +
-	"\n"
+func makeCodeRouteHandlerInterface(routePrefix string, receiverName string, handlerTypeName string, routeMethodName string, interfaceName string, dispatchTypeName string, handlerMethodDecls string) string {
+	return "// " + (interfaceName) + " is the set of handler methods invoked by routing logic.\n" +
+		"type " + (interfaceName) + " interface {\n" +
+		(handlerMethodDecls) + "\n" +
+		"}\n" +
+		"\n" +
+		"// " + (dispatchTypeName) + " carry routing logic over handler methods of " + (interfaceName) + ".\n" +
+		"type " + (dispatchTypeName) + " struct {\n" +
+		"\t" + (interfaceName) + "\n" +
+		"}\n" +
+		"\n" +
+		"func (" + (receiverName) + " *" + (handlerTypeName) + ") " + (routeMethodName) + "(w http.ResponseWriter, req *http.Request) (" + (routePrefix + "RouteIdent") + ", error) {\n" +
+		"\treturn (&" + (dispatchTypeName) + "{" + (receiverName) + "})." + (routeMethodName) + "(w, req)\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeErrFragmentSmallerThanExpect(helperSuffix string) string {
+	return "var " + ("errFragmentSmallerThanExpect" + helperSuffix) + " = errors.New(\"remaining path fragment smaller than expect\")\n" +
+		"\n"
+}
 
-const codeFunctionComputePrefixMatching32 = "func computePrefixMatchingDigest32(path string, offset, bound, length int) (uint32, int, error) {\n" +
-	"\tb := offset + length\n" +
-	"\tif b > bound {\n" +
-	"\t\treturn 0, offset, errFragmentSmallerThanExpect\n" +
-	"\t}\n" +
-	"\tvar digest uint32\n" +
-	"\tfor offset < b {\n" +
-	"\t\tch := path[offset]\n" +
-	"\t\toffset++\n" +
-	"\t\tdigest = (digest << 8) | uint32(ch)\n" +
-	"\t}\n" +
-	"\treturn digest, offset, nil\n" +
-	"}\n" +
-	"\n"
+func makeCodeFunctionComputePrefixMatching32(helperSuffix string) string {
+	return "func " + ("computePrefixMatchingDigest32" + helperSuffix) + "(path string, offset, bound, length int) (uint32, int, error) {\n" +
+		"\tb := offset + length\n" +
+		"\tif b > bound {\n" +
+		"\t\treturn 0, offset, " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
+		"\t}\n" +
+		"\tvar digest uint32\n" +
+		"\tfor offset < b {\n" +
+		"\t\tch := path[offset]\n" +
+		"\t\toffset++\n" +
+		"\t\tdigest = (digest << 8) | uint32(ch)\n" +
+		"\t}\n" +
+		"\treturn digest, offset, nil\n" +
+		"}\n" +
+		"\n"
+}
 
-func makeCodeBlockPrefixMatching32Start(routePrefix string, routeMissingIdent string, baseOffset int, digestLength int) string {
-	return "if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound, " + (strconv.FormatInt(int64(digestLength), 10)) + "); nil != err {\n" +
+func makeCodeFunctionComputePrefixMatching64(helperSuffix string) string {
+	return "func " + ("computePrefixMatchingDigest64" + helperSuffix) + "(path string, offset, bound, length int) (uint64, int, error) {\n" +
+		"\tb := offset + length\n" +
+		"\tif b > bound {\n" +
+		"\t\treturn 0, offset, " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
+		"\t}\n" +
+		"\tvar digest uint64\n" +
+		"\tfor offset < b {\n" +
+		"\t\tch := path[offset]\n" +
+		"\t\toffset++\n" +
+		"\t\tdigest = (digest << 8) | uint64(ch)\n" +
+		"\t}\n" +
+		"\treturn digest, offset, nil\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeBlockPrefixMatching32Start(routePrefix string, helperSuffix string, routeMissingIdent string, baseOffset int, digestLength int) string {
+	return "if digest32, reqPathOffset, err = " + ("computePrefixMatchingDigest32" + helperSuffix) + "(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound, " + (strconv.FormatInt(int64(digestLength), 10)) + "); nil != err {\n" +
 		"\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteError")) + ", err\n" +
 		"}\n" +
 		"\n"
@@ -80,6 +207,36 @@ func makeCodeBlockPrefixMatching32Fork(routePrefix string, digestValue uint32, r
 		"\n"
 }
 
+func makeCodeBlockPrefixMatching64Start(routePrefix string, helperSuffix string, routeMissingIdent string, baseOffset int, digestLength int) string {
+	return "if digest64, reqPathOffset, err = " + ("computePrefixMatchingDigest64" + helperSuffix) + "(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound, " + (strconv.FormatInt(int64(digestLength), 10)) + "); nil != err {\n" +
+		"\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteError")) + ", err\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeBlockPrefixMatching64FallbackStart(routePrefix string, helperSuffix string, routeMissingIdent string, baseOffset int, digestLength int, fallbackDigestLength int, fallbackDigestValues string) string {
+	return "if reqPathOffset+" + (strconv.FormatInt(int64(baseOffset+digestLength), 10)) + " > reqPathBound {\n" +
+		"\tif digest32, reqPathOffset, err = " + ("computePrefixMatchingDigest32" + helperSuffix) + "(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound, " + (strconv.FormatInt(int64(fallbackDigestLength), 10)) + "); nil != err {\n" +
+		"\t\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteError")) + ", err\n" +
+		"\t}\n" +
+		"\tswitch digest32 {\n" +
+		"\tcase " + (fallbackDigestValues) + ":\n" +
+		"\t\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteError")) + ", " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
+		"\t}\n" +
+		"\tdigest64 = 0\n" +
+		"} else if digest64, reqPathOffset, err = " + ("computePrefixMatchingDigest64" + helperSuffix) + "(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound, " + (strconv.FormatInt(int64(digestLength), 10)) + "); nil != err {\n" +
+		"\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteError")) + ", err\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeBlockPrefixMatching64Fork(routePrefix string, digestValue uint64, routingLogicCode string) string {
+	return "else if digest64 == " + ("0x" + strconv.FormatUint(digestValue, 16)) + " {\n" +
+		(routingLogicCode) + "\n" +
+		"}\n" +
+		"\n"
+}
+
 func makeCodeBlockFuzzyMatchingBoundCheckNonZero(routePrefix string, routeMissingIdent string, baseOffset int, fuzzyDepth int) string {
 	return "if reqPathOffset = " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset+fuzzyDepth)) + "; reqPathOffset >= reqPathBound {\n" +
 		"\treturn " + (pickNonEmptyIdent(routeMissingIdent, routePrefix+"RouteIncomplete")) + ", nil\n" +
@@ -115,6 +272,28 @@ func makeCodeBlockFuzzyMatchingU8U16Middle(fuzzyByteValue uint32, routingLogicCo
 		"\n"
 }
 
+func makeCodeBlockSwitch(switchExpr string, caseCode string) string {
+	return "switch " + (switchExpr) + " {\n" +
+		(caseCode) + "\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeBlockSwitchCase(caseValue uint64, routingLogicCode string) string {
+	return "case " + ("0x" + strconv.FormatUint(caseValue, 16)) + ":\n" +
+		(routingLogicCode) + "\n" +
+		"\n"
+}
+
+func makeCodeBlockBinarySearch(conditionExpr string, pivotValue uint64, lowerRoutingLogicCode string, upperRoutingLogicCode string) string {
+	return "if " + (conditionExpr) + " < " + ("0x" + strconv.FormatUint(pivotValue, 16)) + " {\n" +
+		(lowerRoutingLogicCode) + "\n" +
+		"} else {\n" +
+		(upperRoutingLogicCode) + "\n" +
+		"}\n" +
+		"\n"
+}
+
 func makeCodeBlockGetParameter(routePrefix string, routeMissingIdent string, paramName string, paramType string, extractFuncName string, baseOffset int, routingLogicCode string) string {
 	return "var " + (paramName) + " " + (paramType) + "\n" +
 		"if " + (paramName) + ", reqPathOffset, err = " + (extractFuncName) + "(reqPath, " + ("reqPathOffset" + codeTemplateGenIntPlus(baseOffset)) + ", reqPathBound); nil != err {\n" +
@@ -124,29 +303,47 @@ func makeCodeBlockGetParameter(routePrefix string, routeMissingIdent string, par
 		"\n"
 }
 
+func makeCodeMethodRouteFunction(routePrefix string, receiverName string, handlerTypeName string, routeFunctionName string, paramDecls string, routingLogicCode string) string {
+	return "func (" + (receiverName) + " *" + (handlerTypeName) + ") " + (routeFunctionName) + "(w http.ResponseWriter, req *http.Request, reqPath string, reqPathOffset, reqPathBound int" + (paramDecls) + ") (" + (routePrefix + "RouteIdent") + ", error) {\n" +
+		"\tvar err error\n" +
+		"\t_ = err\n" +
+		(routingLogicCode) + "\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeBlockInvokeRouteFunction(routePrefix string, receiverName string, routeFunctionName string, paramArgs string) string {
+	return "if ident, err := " + (receiverName) + "." + (routeFunctionName) + "(w, req, reqPath, reqPathOffset, reqPathBound" + (paramArgs) + "); " + (routePrefix + "RouteNone") + " != ident {\n" +
+		"\treturn ident, err\n" +
+		"}\n" +
+		"\n"
+}
+
 func makeCodeBlockNoMatchMethodForInvoke(routePrefix string) string {
 	return "http.Error(w, \"not allow\", http.StatusMethodNotAllowed)\n" +
 		"return " + (routePrefix + "RouteMethodNotAllowed") + ", nil\n" +
 		"\n"
 }
 
-const codeMethodExtractStringBuiltInR01NoSlash = "func extractStringBuiltInR01NoSlash(v string, offset, bound int) (string, int, error) {\n" +
-	"\tvar buf []byte\n" +
-	"\tfor idx := offset; idx < bound; idx++ {\n" +
-	"\t\tif ch := v[idx]; ch != '/' {\n" +
-	"\t\t\tbuf = append(buf, ch)\n" +
-	"\t\t\tcontinue\n" +
-	"\t\t}\n" +
-	"\t\treturn string(buf), idx, nil\n" +
-	"\t}\n" +
-	"\treturn string(buf), bound, nil\n" +
-	"}\n" +
-	"\n"
+func makeCodeMethodExtractStringBuiltInR01NoSlash(helperSuffix string) string {
+	return "func " + ("extractStringBuiltInR01NoSlash" + helperSuffix) + "(v string, offset, bound int) (string, int, error) {\n" +
+		"\tif offset > bound {\n" +
+		"\t\toffset = bound\n" +
+		"\t}\n" +
+		"\tfor idx := offset; idx < bound; idx++ {\n" +
+		"\t\tif v[idx] == '/' {\n" +
+		"\t\t\treturn v[offset:idx], idx, nil\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn v[offset:bound], bound, nil\n" +
+		"}\n" +
+		"\n"
+}
 
-func makeCodeMethodExtractIntBuiltInR01(typeBit string) string {
-	return "func extractInt" + (typeBit) + "BuiltInR01(v string, offset, bound int) (int" + (typeBit) + ", int, error) {\n" +
+func makeCodeMethodExtractIntBuiltInR01(helperSuffix string, typeBit string) string {
+	return "func extractInt" + (typeBit) + ("BuiltInR01" + helperSuffix) + "(v string, offset, bound int) (int" + (typeBit) + ", int, error) {\n" +
 		"\tif bound <= offset {\n" +
-		"\t\treturn 0, offset, errFragmentSmallerThanExpect\n" +
+		"\t\treturn 0, offset, " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
 		"\t}\n" +
 		"\tnegative := false\n" +
 		"\tif ch := v[offset]; '-' == ch {\n" +
@@ -174,10 +371,10 @@ func makeCodeMethodExtractIntBuiltInR01(typeBit string) string {
 		"\n"
 }
 
-func makeCodeMethodExtractUIntBuiltInR02(typeTitle string, typeName string) string {
-	return "func extract" + (typeTitle) + "BuiltInR02(v string, offset, bound int) (" + (typeName) + ", int, error) {\n" +
+func makeCodeMethodExtractUIntBuiltInR02(helperSuffix string, typeTitle string, typeName string) string {
+	return "func extract" + (typeTitle) + ("BuiltInR02" + helperSuffix) + "(v string, offset, bound int) (" + (typeName) + ", int, error) {\n" +
 		"\tif bound <= offset {\n" +
-		"\t\treturn 0, offset, errFragmentSmallerThanExpect\n" +
+		"\t\treturn 0, offset, " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
 		"\t}\n" +
 		"\tvar result " + (typeName) + "\n" +
 		"\tfor idx := offset; idx < bound; idx++ {\n" +
@@ -194,22 +391,24 @@ func makeCodeMethodExtractUIntBuiltInR02(typeTitle string, typeName string) stri
 		"\n"
 }
 
-const codeSupportConstantsExtractHexIntBuiltInR03 = "var filterMaskHexInt32BuiltInR03 = [...]uint16{0x7E, 0, 0x7E, 0x3FF}\n" +
-	"var offsetValueHexInt32BuiltInR03 = [...]byte{9, 0, 9, 0}\n" +
-	"\n"
+func makeCodeSupportConstantsExtractHexIntBuiltInR03(helperSuffix string) string {
+	return "var " + ("filterMaskHexInt32BuiltInR03" + helperSuffix) + " = [...]uint16{0x7E, 0, 0x7E, 0x3FF}\n" +
+		"var " + ("offsetValueHexInt32BuiltInR03" + helperSuffix) + " = [...]byte{9, 0, 9, 0}\n" +
+		"\n"
+}
 
-func makeCodeMethodExtractHexIntBuiltInR03(typeTitle string, typeName string) string {
-	return "func extract" + (typeTitle) + "BuiltInR03(v string, offset, bound int) (" + (typeName) + ", int, error) {\n" +
+func makeCodeMethodExtractHexIntBuiltInR03(helperSuffix string, typeTitle string, typeName string) string {
+	return "func extract" + (typeTitle) + ("BuiltInR03" + helperSuffix) + "(v string, offset, bound int) (" + (typeName) + ", int, error) {\n" +
 		"\tif bound <= offset {\n" +
-		"\t\treturn 0, offset, errFragmentSmallerThanExpect\n" +
+		"\t\treturn 0, offset, " + ("errFragmentSmallerThanExpect" + helperSuffix) + "\n" +
 		"\t}\n" +
 		"\tvar result " + (typeName) + "\n" +
 		"\tfor idx := offset; idx < bound; idx++ {\n" +
 		"\t\tch := v[idx]\n" +
 		"\t\tdigit := (ch & 0x0F)\n" +
 		"\t\tpage := ((ch >> 4) & 0x3)\n" +
-		"\t\tif (filterMaskHexInt32BuiltInR03[page] & (1 << digit)) != 0 {\n" +
-		"\t\t\tresult = result<<4 | " + (typeName) + "(digit+offsetValueHexInt32BuiltInR03[page])\n" +
+		"\t\tif (" + ("filterMaskHexInt32BuiltInR03" + helperSuffix) + "[page] & (1 << digit)) != 0 {\n" +
+		"\t\t\tresult = result<<4 | " + (typeName) + "(digit+" + ("offsetValueHexInt32BuiltInR03" + helperSuffix) + "[page])\n" +
 		"\t\t\tcontinue\n" +
 		"\t\t}\n" +
 		"\t\treturn result, idx, nil\n" +
@@ -219,23 +418,388 @@ func makeCodeMethodExtractHexIntBuiltInR03(typeTitle string, typeName string) st
 		"\n"
 }
 
-func makeCodeMethodExtractByteSliceStringBitMasked(typeTitle string, typeName string, typeCasting string, rangeBase byte, bitmaskIdent string, bitmaskSlice []uint32) string {
+func makeCodeMethodExtractByteSliceStringBitMasked(typeTitle string, typeName string, valueExpr string, rangeBase byte, bitmaskIdent string, bitmaskSlice []uint32) string {
 	return "var filterMask" + (typeTitle) + "Rx" + (bitmaskIdent) + " = [...]uint32{0x" + (strconv.FormatInt(int64(bitmaskSlice[0]), 16)) + ", 0x" + (strconv.FormatInt(int64(bitmaskSlice[1]), 16)) + ", 0x" + (strconv.FormatInt(int64(bitmaskSlice[2]), 16)) + ", 0x" + (strconv.FormatInt(int64(bitmaskSlice[3]), 16)) + "}\n" +
 		"\n" +
 		"func extract" + (typeTitle) + "Rx" + (bitmaskIdent) + "(v string, offset, bound int) (" + (typeName) + ", int, error) {\n" +
-		"\tvar result []byte\n" +
-		"\tfor idx := offset; idx < bound; idx++ {\n" +
-		"\t\tch := v[idx]\n" +
-		"\t\tmoved := ch - " + ("0x" + strconv.FormatInt(int64(rangeBase), 16)) + "\n" +
+		"\tif offset > bound {\n" +
+		"\t\toffset = bound\n" +
+		"\t}\n" +
+		"\tidx := offset\n" +
+		"\tfor ; idx < bound; idx++ {\n" +
+		"\t\tmoved := v[idx] - " + ("0x" + strconv.FormatInt(int64(rangeBase), 16)) + "\n" +
 		"\t\tpage := (moved >> 5) & 0x3\n" +
 		"\t\tnbit := moved & 0x1F\n" +
-		"\t\tif 0 != (filterMask" + (typeTitle) + "Rx" + (bitmaskIdent) + "[page] & (1 << nbit)) {\n" +
-		"\t\t\tresult = append(result, ch)\n" +
+		"\t\tif 0 == (filterMask" + (typeTitle) + "Rx" + (bitmaskIdent) + "[page] & (1 << nbit)) {\n" +
+		"\t\t\tbreak\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn " + (valueExpr) + ", idx, nil\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeConstRouteProgramCode(helperSuffix string, programLiteral string) string {
+	return "// " + ("routeProgramCode" + helperSuffix) + " is fanout decision tree serialized for " + ("evaluateRouteProgram" + helperSuffix) + ".\n" +
+		"const " + ("routeProgramCode" + helperSuffix) + " = " + (programLiteral) + "\n" +
+		"\n"
+}
+
+func makeCodeRouteProgramInterpreter(helperSuffix string, runtimeHelperSuffix string) string {
+	return "type " + ("routeProgramSlot" + helperSuffix) + " struct {\n" +
+		"\tvalue uint64\n" +
+		"\tstart int\n" +
+		"\tend   int\n" +
+		"}\n" +
+		"\n" +
+		"type " + ("routeProgramState" + helperSuffix) + " struct {\n" +
+		"\tmethod           string\n" +
+		"\treqPath          string\n" +
+		"\toffset           int\n" +
+		"\tbound            int\n" +
+		"\tident            int\n" +
+		"\tcallSite         int\n" +
+		"\tpathOffset       int\n" +
+		"\tmethodNotAllowed bool\n" +
+		"\tslots            []" + ("routeProgramSlot" + helperSuffix) + "\n" +
+		"}\n" +
+		"\n" +
+		"var " + ("routeProgramMethods" + helperSuffix) + " = [...]string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}\n" +
+		"\n" +
+		"func " + ("readRouteProgramUvarint" + helperSuffix) + "(program string, pc int) (uint64, int) {\n" +
+		"\tvar v uint64\n" +
+		"\tfor shift := uint(0); ; shift += 7 {\n" +
+		"\t\tb := program[pc]\n" +
+		"\t\tpc++\n" +
+		"\t\tv |= uint64(b&0x7F) << shift\n" +
+		"\t\tif b < 0x80 {\n" +
+		"\t\t\treturn v, pc\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"}\n" +
+		"\n" +
+		"// " + ("extractRouteProgramValue" + helperSuffix) + " extract parameter with extractor at given program counter:\n" +
+		"// 1 - any byte except slash, 2 - signed decimal, 3 - unsigned decimal,\n" +
+		"// 4 - hexadecimal, 5 - bytes in range base and 128 bits mask following the kind.\n" +
+		"func " + ("extractRouteProgramValue" + helperSuffix) + "(program string, pc int, v string, offset, bound int) (slot " + ("routeProgramSlot" + helperSuffix) + ", err error) {\n" +
+		"\tkind := program[pc]\n" +
+		"\tif (kind == 1) || (kind == 5) {\n" +
+		"\t\tif offset > bound {\n" +
+		"\t\t\toffset = bound\n" +
+		"\t\t}\n" +
+		"\t} else if bound <= offset {\n" +
+		"\t\treturn " + ("routeProgramSlot" + helperSuffix) + "{start: offset, end: offset}, " + ("errFragmentSmallerThanExpect" + runtimeHelperSuffix) + "\n" +
+		"\t}\n" +
+		"\tslot.start = offset\n" +
+		"\tnegative := false\n" +
+		"\tif (kind == 2) && (v[offset] == '-') {\n" +
+		"\t\tnegative = true\n" +
+		"\t\toffset++\n" +
+		"\t}\n" +
+		"\tidx := offset\n" +
+		"scanLoop:\n" +
+		"\tfor ; idx < bound; idx++ {\n" +
+		"\t\tch := v[idx]\n" +
+		"\t\tdigit := ch & 0x0F\n" +
+		"\t\tswitch kind {\n" +
+		"\t\tcase 1:\n" +
+		"\t\t\tif ch == '/' {\n" +
+		"\t\t\t\tbreak scanLoop\n" +
+		"\t\t\t}\n" +
+		"\t\tcase 2, 3:\n" +
+		"\t\t\tif ((ch & 0xF0) != 0x30) || ((1023 & (1 << digit)) == 0) {\n" +
+		"\t\t\t\tbreak scanLoop\n" +
+		"\t\t\t}\n" +
+		"\t\t\tslot.value = slot.value*10 + uint64(digit)\n" +
+		"\t\tcase 4:\n" +
+		"\t\t\tpage := (ch >> 4) & 0x3\n" +
+		"\t\t\tif (uint16(uint64(0x03FF007E0000007E)>>(page*16)) & (1 << digit)) == 0 {\n" +
+		"\t\t\t\tbreak scanLoop\n" +
+		"\t\t\t}\n" +
+		"\t\t\tif (page & 0x1) == 0 {\n" +
+		"\t\t\t\tdigit += 9\n" +
+		"\t\t\t}\n" +
+		"\t\t\tslot.value = slot.value<<4 | uint64(digit)\n" +
+		"\t\tcase 5:\n" +
+		"\t\t\tmoved := (ch - program[pc+1]) & 0x7F\n" +
+		"\t\t\tif (program[pc+2+int(moved>>3)] & (1 << (moved & 0x7))) == 0 {\n" +
+		"\t\t\t\tbreak scanLoop\n" +
+		"\t\t\t}\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\tif negative {\n" +
+		"\t\tslot.value = -slot.value\n" +
+		"\t}\n" +
+		"\tslot.end = idx\n" +
+		"\treturn slot, nil\n" +
+		"}\n" +
+		"\n" +
+		"// " + ("evaluateRouteProgram" + helperSuffix) + " evaluate operations of program in [pc, end) until one of them decides the route.\n" +
+		"func " + ("evaluateRouteProgram" + helperSuffix) + "(state *" + ("routeProgramState" + helperSuffix) + ", program string, pc, end int) (bool, error) {\n" +
+		"\tvar baseOffset, v uint64\n" +
+		"\tfor pc < end {\n" +
+		"\t\top := program[pc]\n" +
+		"\t\tbaseOffset, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc+1)\n" +
+		"\t\tswitch op {\n" +
+		"\t\tcase 1, 2, 3, 6: // prefix matching, fuzzy matching (U8, U16), prefix matching with fallback\n" +
+		"\t\t\tvar depth, failIdent, fallIdent, fallbackDepth, fallbackLength, bodyLength, key uint64\n" +
+		"\t\t\tvar fallbackPC int\n" +
+		"\t\t\tdepth, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tfailIdent, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tfallIdent, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tif op == 6 {\n" +
+		"\t\t\t\tfallbackDepth, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\t\tfallbackLength, fallbackPC = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\t\tpc = fallbackPC + int(fallbackLength)\n" +
+		"\t\t\t}\n" +
+		"\t\t\tbodyLength, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tnextPC := pc + int(bodyLength)\n" +
+		"\t\t\tif (op == 1) || (op == 6) {\n" +
+		"\t\t\t\toffset := state.offset + int(baseOffset)\n" +
+		"\t\t\t\tb := offset + int(depth)\n" +
+		"\t\t\t\tif (op == 6) && (b > state.bound) {\n" +
+		"\t\t\t\t\tb = offset + int(fallbackDepth)\n" +
+		"\t\t\t\t} else {\n" +
+		"\t\t\t\t\tfallbackLength = 0\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tif b > state.bound {\n" +
+		"\t\t\t\t\tstate.offset = offset\n" +
+		"\t\t\t\t\tstate.ident = int(failIdent)\n" +
+		"\t\t\t\t\treturn true, " + ("errFragmentSmallerThanExpect" + runtimeHelperSuffix) + "\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tfor ; offset < b; offset++ {\n" +
+		"\t\t\t\t\tkey = (key << 8) | uint64(state.reqPath[offset])\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tstate.offset = offset\n" +
+		"\t\t\t\tfor fallbackEnd := fallbackPC + int(fallbackLength); fallbackPC < fallbackEnd; {\n" +
+		"\t\t\t\t\tif v, fallbackPC = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, fallbackPC); v == key {\n" +
+		"\t\t\t\t\t\tstate.ident = int(failIdent)\n" +
+		"\t\t\t\t\t\treturn true, " + ("errFragmentSmallerThanExpect" + runtimeHelperSuffix) + "\n" +
+		"\t\t\t\t\t}\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tif 0 != fallbackLength {\n" +
+		"\t\t\t\t\tkey = 0\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t} else {\n" +
+		"\t\t\t\tif state.offset += int(baseOffset + depth); state.offset >= state.bound {\n" +
+		"\t\t\t\t\tstate.ident = int(failIdent)\n" +
+		"\t\t\t\t\treturn true, nil\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tkey = uint64(state.reqPath[state.offset])\n" +
+		"\t\t\t\tif op == 3 {\n" +
+		"\t\t\t\t\tkey |= uint64(state.reqPath[state.offset-1]) << 8\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t}\n" +
+		"\t\t\tfor pc < nextPC {\n" +
+		"\t\t\t\tv, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\t\tbodyLength, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\t\tif v == key {\n" +
+		"\t\t\t\t\tif done, err := " + ("evaluateRouteProgram" + helperSuffix) + "(state, program, pc, pc+int(bodyLength)); done {\n" +
+		"\t\t\t\t\t\treturn true, err\n" +
+		"\t\t\t\t\t}\n" +
+		"\t\t\t\t\tbreak\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t\tpc += int(bodyLength)\n" +
+		"\t\t\t}\n" +
+		"\t\t\tpc = nextPC\n" +
+		"\t\t\tif 0 != fallIdent {\n" +
+		"\t\t\t\tstate.ident = int(fallIdent)\n" +
+		"\t\t\t\treturn true, nil\n" +
+		"\t\t\t}\n" +
+		"\t\tcase 4: // get parameter\n" +
+		"\t\t\tvar slotIndex, failIdent, bodyLength uint64\n" +
+		"\t\t\textractorPC := pc\n" +
+		"\t\t\tif program[pc] == 5 {\n" +
+		"\t\t\t\tpc += 18\n" +
+		"\t\t\t} else {\n" +
+		"\t\t\t\tpc++\n" +
+		"\t\t\t}\n" +
+		"\t\t\tslotIndex, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tfailIdent, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tbodyLength, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tslot, err := " + ("extractRouteProgramValue" + helperSuffix) + "(program, extractorPC, state.reqPath, state.offset+int(baseOffset), state.bound)\n" +
+		"\t\t\tstate.offset = slot.end\n" +
+		"\t\t\tif nil != err {\n" +
+		"\t\t\t\tstate.ident = int(failIdent)\n" +
+		"\t\t\t\treturn true, err\n" +
+		"\t\t\t}\n" +
+		"\t\t\tstate.slots[slotIndex] = slot\n" +
+		"\t\t\tif done, err := " + ("evaluateRouteProgram" + helperSuffix) + "(state, program, pc, pc+int(bodyLength)); done {\n" +
+		"\t\t\t\treturn true, err\n" +
+		"\t\t\t}\n" +
+		"\t\t\tpc += int(bodyLength)\n" +
+		"\t\tcase 5: // invoke handler\n" +
+		"\t\t\tvar count, callSite uint64\n" +
+		"\t\t\tcount, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc)\n" +
+		"\t\t\tfor ; count > 0; count-- {\n" +
+		"\t\t\t\tmethod := " + ("routeProgramMethods" + helperSuffix) + "[program[pc]]\n" +
+		"\t\t\t\tcallSite, pc = " + ("readRouteProgramUvarint" + helperSuffix) + "(program, pc+1)\n" +
+		"\t\t\t\tif method == state.method {\n" +
+		"\t\t\t\t\tstate.callSite = int(callSite)\n" +
+		"\t\t\t\t\tstate.pathOffset = state.offset + int(baseOffset)\n" +
+		"\t\t\t\t\treturn true, nil\n" +
+		"\t\t\t\t}\n" +
+		"\t\t\t}\n" +
+		"\t\t\tstate.methodNotAllowed = true\n" +
+		"\t\t\treturn true, nil\n" +
+		"\t\tdefault:\n" +
+		"\t\t\treturn false, nil\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn false, nil\n" +
+		"}\n" +
+		"\n" +
+		"func " + ("runRouteProgram" + helperSuffix) + "(state *" + ("routeProgramState" + helperSuffix) + ", program string) (bool, error) {\n" +
+		"\tfor state.offset < state.bound {\n" +
+		"\t\tif state.reqPath[state.offset] == '/' {\n" +
+		"\t\t\tstate.offset++\n" +
+		"\t\t\tbreak\n" +
+		"\t\t}\n" +
+		"\t\tstate.offset++\n" +
+		"\t}\n" +
+		"\tif state.offset >= state.bound {\n" +
+		"\t\treturn false, nil\n" +
+		"\t}\n" +
+		"\treturn " + ("evaluateRouteProgram" + helperSuffix) + "(state, program, 0, len(program))\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeMethodRouteProgramEnterance(routePrefix string, helperSuffix string, receiverName string, handlerTypeName string, routeMethodName string, slotCount int, resultIdentExpr string) string {
+	return "func (" + (receiverName) + " *" + (handlerTypeName) + ") " + (routeMethodName) + "(w http.ResponseWriter, req *http.Request) (" + (routePrefix + "RouteIdent") + ", error) {\n" +
+		"\treqPath := req.URL.Path\n" +
+		"\tvar slots [" + (strconv.FormatInt(int64(slotCount), 10)) + "]" + ("routeProgramSlot" + helperSuffix) + "\n" +
+		"\tstate := " + ("routeProgramState" + helperSuffix) + "{\n" +
+		"\t\tmethod:  req.Method,\n" +
+		"\t\treqPath: reqPath,\n" +
+		"\t\tbound:   len(reqPath),\n" +
+		"\t\tslots:   slots[:],\n" +
+		"\t}\n" +
+		"\tdone, err := " + ("runRouteProgram" + helperSuffix) + "(&state, " + ("routeProgramCode" + helperSuffix) + ")\n" +
+		"\tif !done {\n" +
+		"\t\treturn " + (routePrefix + "RouteNone") + ", nil\n" +
+		"\t}\n" +
+		"\tif state.methodNotAllowed {\n" +
+		"\t\thttp.Error(w, \"not allow\", http.StatusMethodNotAllowed)\n" +
+		"\t\treturn " + (routePrefix + "RouteMethodNotAllowed") + ", nil\n" +
+		"\t}\n" +
+		"\tif 0 == state.callSite {\n" +
+		"\t\treturn " + (resultIdentExpr) + ", err\n" +
+		"\t}\n" +
+		"\treturn " + ("routeProgramHandlers" + helperSuffix) + "[state.callSite-1](" + (receiverName) + ", w, req, reqPath, state.pathOffset, slots)\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeVarRouteProgramHandlers(routePrefix string, helperSuffix string, handlerTypeName string, slotCount int, handlerFuncs string) string {
+	return "// " + ("routeProgramHandlers" + helperSuffix) + " invoke handler of call site decided by route program.\n" +
+		"var " + ("routeProgramHandlers" + helperSuffix) + " []func(h *" + (handlerTypeName) + ", w http.ResponseWriter, req *http.Request, reqPath string, pathOffset int, slots [" + (strconv.FormatInt(int64(slotCount), 10)) + "]" + ("routeProgramSlot" + helperSuffix) + ") (" + (routePrefix + "RouteIdent") + ", error)\n" +
+		"\n" +
+		"func init() {\n" +
+		"\t" + ("routeProgramHandlers" + helperSuffix) + " = []func(h *" + (handlerTypeName) + ", w http.ResponseWriter, req *http.Request, reqPath string, pathOffset int, slots [" + (strconv.FormatInt(int64(slotCount), 10)) + "]" + ("routeProgramSlot" + helperSuffix) + ") (" + (routePrefix + "RouteIdent") + ", error){\n" +
+		(handlerFuncs) + "\n" +
+		"\t}\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeFuncRouteProgramHandler(routePrefix string, helperSuffix string, receiverName string, handlerTypeName string, slotCount int, invokeHandlerCode string) string {
+	return "func(" + (receiverName) + " *" + (handlerTypeName) + ", w http.ResponseWriter, req *http.Request, reqPath string, pathOffset int, slots [" + (strconv.FormatInt(int64(slotCount), 10)) + "]" + ("routeProgramSlot" + helperSuffix) + ") (" + (routePrefix + "RouteIdent") + ", error) {\n" +
+		(invokeHandlerCode) + "\n" +
+		"},\n" +
+		"\n"
+}
+
+func makeCodeTypeRouteTestRecorder(receiverName string, recorderTypeName string) string {
+	return "type " + (recorderTypeName) + " struct {\n" +
+		"\thandlerName string\n" +
+		"\tpathOffset  int\n" +
+		"\tparams      []interface{}\n" +
+		"}\n" +
+		"\n" +
+		"func (" + (receiverName) + " *" + (recorderTypeName) + ") record(handlerName string, pathOffset int, params ...interface{}) {\n" +
+		"\t" + (receiverName) + ".handlerName = handlerName\n" +
+		"\t" + (receiverName) + ".pathOffset = pathOffset\n" +
+		"\t" + (receiverName) + ".params = params\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeFunctionRouteTest(routePrefix string, recorderTypeName string, dispatchTypeName string, routeMethodName string, testFunctionName string, routeTestCaseElements string) string {
+	return "func " + (testFunctionName) + "(t *testing.T) {\n" +
+		"\ttestCases := []struct {\n" +
+		"\t\tmethod      string\n" +
+		"\t\tpath        string\n" +
+		"\t\tident       " + (routePrefix + "RouteIdent") + "\n" +
+		"\t\thandlerName string\n" +
+		"\t\tparams      []interface{}\n" +
+		"\t}{\n" +
+		(routeTestCaseElements) + "\n" +
+		"\t}\n" +
+		"\tfor _, tc := range testCases {\n" +
+		"\t\trecorder := &" + (recorderTypeName) + "{}\n" +
+		"\t\treq := &http.Request{\n" +
+		"\t\t\tMethod: tc.method,\n" +
+		"\t\t\tURL:    &url.URL{Path: tc.path},\n" +
+		"\t\t}\n" +
+		"\t\tident, err := (&" + (dispatchTypeName) + "{recorder})." + (routeMethodName) + "(httptest.NewRecorder(), req)\n" +
+		"\t\tif nil != err {\n" +
+		"\t\t\tt.Errorf(\"%s %s: unexpected error: %v\", tc.method, tc.path, err)\n" +
 		"\t\t\tcontinue\n" +
 		"\t\t}\n" +
-		"\t\treturn " + (typeCasting) + "(result), idx, nil\n" +
+		"\t\tif ident != tc.ident {\n" +
+		"\t\t\tt.Errorf(\"%s %s: expect route ident %v but got %v\", tc.method, tc.path, tc.ident, ident)\n" +
+		"\t\t\tcontinue\n" +
+		"\t\t}\n" +
+		"\t\tif recorder.handlerName != tc.handlerName {\n" +
+		"\t\t\tt.Errorf(\"%s %s: expect handler %q but got %q\", tc.method, tc.path, tc.handlerName, recorder.handlerName)\n" +
+		"\t\t\tcontinue\n" +
+		"\t\t}\n" +
+		"\t\tif \"\" == tc.handlerName {\n" +
+		"\t\t\tcontinue\n" +
+		"\t\t}\n" +
+		"\t\tif (recorder.pathOffset < 0) || (recorder.pathOffset > len(tc.path)) {\n" +
+		"\t\t\tt.Errorf(\"%s %s: path offset out of range: %d\", tc.method, tc.path, recorder.pathOffset)\n" +
+		"\t\t}\n" +
+		"\t\tif !reflect.DeepEqual(recorder.params, tc.params) {\n" +
+		"\t\t\tt.Errorf(\"%s %s: expect parameters %#v but got %#v\", tc.method, tc.path, tc.params, recorder.params)\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"}\n" +
+		"\n"
+}
+
+func makeCodeFunctionRouteFuzz(routePrefix string, recorderTypeName string, dispatchTypeName string, routeMethodName string, fuzzFunctionName string, routeFuzzSeedElements string) string {
+	return "func " + (fuzzFunctionName) + "(f *testing.F) {\n" +
+		"\tseeds := []struct {\n" +
+		"\t\tmethod string\n" +
+		"\t\tpath   string\n" +
+		"\t}{\n" +
+		(routeFuzzSeedElements) + "\n" +
 		"\t}\n" +
-		"\treturn " + (typeCasting) + "(result), bound, nil\n" +
+		"\tfor _, seed := range seeds {\n" +
+		"\t\tf.Add(seed.method, seed.path)\n" +
+		"\t}\n" +
+		"\tf.Fuzz(func(t *testing.T, method string, reqPath string) {\n" +
+		"\t\trecorder := &" + (recorderTypeName) + "{\n" +
+		"\t\t\tpathOffset: -1,\n" +
+		"\t\t}\n" +
+		"\t\treq := &http.Request{\n" +
+		"\t\t\tMethod: method,\n" +
+		"\t\t\tURL:    &url.URL{Path: reqPath},\n" +
+		"\t\t}\n" +
+		"\t\tident, err := (&" + (dispatchTypeName) + "{recorder})." + (routeMethodName) + "(httptest.NewRecorder(), req)\n" +
+		"\t\tinvoked := (\"\" != recorder.handlerName)\n" +
+		"\t\tif invoked != (ident > " + (routePrefix + "RouteSuccess") + ") {\n" +
+		"\t\t\tt.Fatalf(\"%s %q: route ident %v mismatch with handler invocation %q\", method, reqPath, ident, recorder.handlerName)\n" +
+		"\t\t}\n" +
+		"\t\tif invoked && (nil != err) {\n" +
+		"\t\t\tt.Fatalf(\"%s %q: error with handler invoked: %v\", method, reqPath, err)\n" +
+		"\t\t}\n" +
+		"\t\tif invoked && ((recorder.pathOffset < 0) || (recorder.pathOffset > len(reqPath))) {\n" +
+		"\t\t\tt.Fatalf(\"%s %q: path offset out of range: %d\", method, reqPath, recorder.pathOffset)\n" +
+		"\t\t}\n" +
+		"\t})\n" +
 		"}\n" +
 		"\n"
 }
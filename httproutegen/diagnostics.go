@@ -0,0 +1,113 @@
+package httproutegen
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// DiagnosticSeverity is severity level of diagnostic.
+type DiagnosticSeverity int
+
+// Severity levels of diagnostics.
+const (
+	DiagnosticInfo DiagnosticSeverity = iota
+	DiagnosticWarning
+	DiagnosticError
+)
+
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case DiagnosticInfo:
+		return "INFO"
+	case DiagnosticWarning:
+		return "WARN"
+	case DiagnosticError:
+		return "ERROR"
+	}
+	return "DiagnosticSeverity(" + strconv.FormatInt(int64(s), 10) + ")"
+}
+
+// DiagnosticCode is machine-readable code of diagnostic.
+type DiagnosticCode string
+
+// Codes of diagnostics.
+const (
+	DiagnosticTerminateSerialNotFullyMatch DiagnosticCode = "terminate-serial-not-fully-match"
+	DiagnosticPrefixMatchingCoverageShrink DiagnosticCode = "prefix-matching-coverage-shrink"
+	DiagnosticFuzzyMatchingCoverageShrink  DiagnosticCode = "fuzzy-matching-coverage-shrink"
+	DiagnosticParentForkExisted            DiagnosticCode = "parent-fork-existed"
+	DiagnosticByteOutOfRange               DiagnosticCode = "byte-out-of-range"
+	DiagnosticUnknownExtractFunction       DiagnosticCode = "unknown-extract-function"
+	DiagnosticUnknownHandlerAssignment     DiagnosticCode = "unknown-handler-assignment"
+	DiagnosticSequenceIndexOutOfRange      DiagnosticCode = "sequence-index-out-of-range"
+	DiagnosticLiteralDigestTruncated       DiagnosticCode = "literal-digest-truncated"
+	DiagnosticHandlerSharedByRoutes        DiagnosticCode = "handler-shared-by-routes"
+)
+
+// Diagnostic is one message reported by fanout expansion or code generation.
+// RouteIdent is empty if the message is not about a specific route.
+type Diagnostic struct {
+	Severity   DiagnosticSeverity `json:"severity"`
+	Code       DiagnosticCode     `json:"code"`
+	RouteIdent string             `json:"route,omitempty"`
+	Message    string             `json:"message"`
+}
+
+func (d *Diagnostic) String() string {
+	text := d.Severity.String() + ": [" + string(d.Code) + "] "
+	if "" != d.RouteIdent {
+		text += d.RouteIdent + ": "
+	}
+	return text + d.Message
+}
+
+// Diagnostics receive diagnostics from fanout expansion and code generation.
+type Diagnostics interface {
+	Report(d *Diagnostic)
+}
+
+// LogDiagnostics write diagnostics to standard logger.
+// It is used when no diagnostics is given.
+type LogDiagnostics struct{}
+
+// Report implements Diagnostics interface.
+func (LogDiagnostics) Report(d *Diagnostic) {
+	log.Print(d.String())
+}
+
+func reportDiagnostic(diagnostics Diagnostics, severity DiagnosticSeverity, code DiagnosticCode, routeIdent string, format string, a ...interface{}) {
+	if nil == diagnostics {
+		diagnostics = LogDiagnostics{}
+	}
+	diagnostics.Report(&Diagnostic{
+		Severity:   severity,
+		Code:       code,
+		RouteIdent: routeIdent,
+		Message:    fmt.Sprintf(format, a...),
+	})
+}
+
+// CountingDiagnostics pass diagnostics to Target and count warnings and errors.
+// Target is LogDiagnostics when it is nil.
+type CountingDiagnostics struct {
+	Target Diagnostics
+
+	WarningCount int
+	ErrorCount   int
+}
+
+// Report implements Diagnostics interface.
+func (c *CountingDiagnostics) Report(d *Diagnostic) {
+	switch d.Severity {
+	case DiagnosticWarning:
+		c.WarningCount++
+	case DiagnosticError:
+		c.ErrorCount++
+	}
+	if nil == c.Target {
+		LogDiagnostics{}.Report(d)
+		return
+	}
+	c.Target.Report(d)
+}
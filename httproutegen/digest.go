@@ -1,14 +1,12 @@
 package httproutegen
 
-import (
-	"log"
-)
-
 // ComputeLiteralDigest generate literal digest from string.
+// Only the last 8 bytes are kept in digest of longer string and a warning is reported.
 func ComputeLiteralDigest(literal string) (digest uint64) {
 	b := []byte(literal)
 	if len(b) > 8 {
-		log.Printf("WARN: compute literal digest against string with length > 8: %v", literal)
+		reportDiagnostic(nil, DiagnosticWarning, DiagnosticLiteralDigestTruncated, "",
+			"only last 8 bytes are kept in digest: %s", literal)
 	}
 	for _, ch := range b {
 		digest = (digest << 8) | uint64(ch)
@@ -0,0 +1,137 @@
+package httproutegen
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+func escapeDOTLabelText(text string) string {
+	text = strings.Replace(text, "\\", "\\\\", -1)
+	return strings.Replace(text, "\"", "\\\"", -1)
+}
+
+func decodeDigestLiteral(value uint64, length int) string {
+	var b []byte
+	for idx := length - 1; idx >= 0; idx-- {
+		b = append(b, byte(value>>uint(8*idx)))
+	}
+	return strconv.Quote(string(b))
+}
+
+func intersectTerminateSerials(serials1, serials2 []int32) bool {
+	for _, s1 := range serials1 {
+		for _, s2 := range serials2 {
+			if s1 == s2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type fanoutForkDOTWriter struct {
+	w          io.Writer
+	nodeSerial int
+}
+
+func (dotWriter *fanoutForkDOTWriter) fuzzyTrack(fork *FanoutFork) (trackSet []*FanoutFuzzyTrackSet, trackDepth, length int) {
+	if fork.FuzzyModeBit == 16 {
+		return fork.FuzzyTracker.BestU16, fork.FuzzyTracker.BestU16Depth, 2
+	}
+	return fork.FuzzyTracker.BestU8, fork.FuzzyTracker.BestU8Depth, 1
+}
+
+func (dotWriter *fanoutForkDOTWriter) nodeLabel(fork *FanoutFork) (lines []string) {
+	lines = append(lines, fork.LogicType.String())
+	if "" != fork.AreaName {
+		lines = append(lines, "area: "+fork.AreaName)
+	}
+	lines = append(lines, "base offset: "+strconv.FormatInt(int64(fork.BaseOffset), 10))
+	switch fork.LogicType {
+	case LogicTypePrefixMatching:
+		lines = append(lines, "digest length: "+strconv.FormatInt(int64(fork.PrefixLiteralDigests.Depth), 10))
+		for _, digestSet := range fork.PrefixLiteralDigests.Digests {
+			lines = append(lines, "digest: "+decodeDigestLiteral(digestSet.Value, fork.PrefixLiteralDigests.Depth))
+		}
+		if fallbackDigests := fork.PrefixFallbackDigests; nil != fallbackDigests {
+			for _, digestSet := range fallbackDigests.Digests {
+				lines = append(lines, "fallback digest: "+decodeDigestLiteral(digestSet.Value, fallbackDigests.Depth))
+			}
+		}
+	case LogicTypeFuzzyMatching:
+		trackSet, trackDepth, length := dotWriter.fuzzyTrack(fork)
+		lines = append(lines, "fuzzy mode: U"+strconv.FormatInt(int64(fork.FuzzyModeBit), 10))
+		lines = append(lines, "fuzzy depth: "+strconv.FormatInt(int64(trackDepth), 10))
+		for _, s := range trackSet {
+			lines = append(lines, "fuzzy: "+decodeDigestLiteral(uint64(s.Value), length))
+		}
+	case LogicTypeGetParameter:
+		lines = append(lines, "sequence: "+strconv.FormatInt(int64(fork.SequenceIndex), 10)+" {"+fork.SequenceVarName+"}")
+	case LogicTypeInvokeHandler:
+		routeEntry := fork.InvokeHandlerFanout.Route
+		lines = append(lines, "route: "+routeEntry.Ident)
+		for _, invokeProfile := range routeEntry.HandlerProfile.InvokeProfiles {
+			lines = append(lines, invokeProfile.RequestMethod+": "+invokeProfile.HandlerName)
+		}
+	}
+	return
+}
+
+func (dotWriter *fanoutForkDOTWriter) edgeLabel(fork, childFork *FanoutFork) string {
+	switch fork.LogicType {
+	case LogicTypePrefixMatching:
+		for _, digestSet := range fork.PrefixLiteralDigests.Digests {
+			if intersectTerminateSerials(digestSet.TerminateSerials, childFork.CoveredTerminals) {
+				return decodeDigestLiteral(digestSet.Value, fork.PrefixLiteralDigests.Depth)
+			}
+		}
+	case LogicTypeFuzzyMatching:
+		trackSet, _, length := dotWriter.fuzzyTrack(fork)
+		for _, s := range trackSet {
+			if intersectTerminateSerials(s.TerminateSerials, childFork.CoveredTerminals) {
+				return decodeDigestLiteral(uint64(s.Value), length)
+			}
+		}
+	}
+	return ""
+}
+
+func (dotWriter *fanoutForkDOTWriter) writeFork(fork *FanoutFork) (nodeName string, err error) {
+	dotWriter.nodeSerial++
+	nodeName = "fork" + strconv.FormatInt(int64(dotWriter.nodeSerial), 10)
+	label := escapeDOTLabelText(strings.Join(dotWriter.nodeLabel(fork), "\n"))
+	label = strings.Replace(label, "\n", "\\l", -1) + "\\l"
+	if _, err = io.WriteString(dotWriter.w, "  "+nodeName+" [label=\""+label+"\"];\n"); nil != err {
+		return
+	}
+	for _, childFork := range fork.ChildForks {
+		childNodeName, err := dotWriter.writeFork(childFork)
+		if nil != err {
+			return "", err
+		}
+		edgeText := "  " + nodeName + " -> " + childNodeName
+		if edgeLabel := dotWriter.edgeLabel(fork, childFork); "" != edgeLabel {
+			edgeText += " [label=\"" + escapeDOTLabelText(edgeLabel) + "\"]"
+		}
+		if _, err = io.WriteString(dotWriter.w, edgeText+";\n"); nil != err {
+			return "", err
+		}
+	}
+	return
+}
+
+// WriteFanoutForkDOT write fanout fork tree in Graphviz DOT format.
+func WriteFanoutForkDOT(w io.Writer, rootFanoutFork *FanoutFork) (err error) {
+	if _, err = io.WriteString(w, "digraph fanout {\n  node [shape=box, fontname=\"monospace\"];\n"); nil != err {
+		return
+	}
+	dotWriter := fanoutForkDOTWriter{
+		w: w,
+	}
+	if _, err = dotWriter.writeFork(rootFanoutFork); nil != err {
+		return
+	}
+	_, err = io.WriteString(w, "}\n")
+	return
+}
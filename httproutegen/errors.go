@@ -1,5 +1,20 @@
 package httproutegen
 
+import (
+	"errors"
+	"strings"
+)
+
+// ErrFragmentSmallerThanExpect indicate remaining path is shorter than the matching logic requires.
+// It is the counterpart of errFragmentSmallerThanExpect in generated code.
+var ErrFragmentSmallerThanExpect = errors.New("remaining path fragment smaller than expect")
+
+// ErrTestRequiresHandlerInterface indicate test is requested for route code generated without UseHandlerInterface.
+var ErrTestRequiresHandlerInterface = errors.New("generated test requires route code with UseHandlerInterface")
+
+// ErrCodeFilePathRequired indicate Generate() is invoked on instance not created by OpenCodeGenerateInstance().
+var ErrCodeFilePathRequired = errors.New("code file path is required, use GenerateTo() or GenerateFile() instead")
+
 // ErrConflictConfiguration represent conflict in configuration
 type ErrConflictConfiguration struct {
 	Component string
@@ -32,3 +47,68 @@ func newErrParseComponent(componentIdent string, err error) error {
 func (e *ErrParseComponent) Error() string {
 	return "ErrParseComponent: component=" + e.Component + ", error=" + e.Err.Error()
 }
+
+// ErrHandlerTypeNotFound indicate handler type cannot be found in type-checked package.
+type ErrHandlerTypeNotFound struct {
+	PackageFolderPath string
+	HandlerTypeName   string
+}
+
+func (e *ErrHandlerTypeNotFound) Error() string {
+	return "ErrHandlerTypeNotFound: type " + e.HandlerTypeName + " is not found in package at " + e.PackageFolderPath
+}
+
+// ErrFanoutConflict indicate routes which cannot be separated by fanout expansion.
+type ErrFanoutConflict struct {
+	RouteIdents []string
+	Message     string
+}
+
+func newErrFanoutConflict(symbols []FanoutSymbol, message string) error {
+	return &ErrFanoutConflict{
+		RouteIdents: routeIdentsOfSymbols(symbols),
+		Message:     message,
+	}
+}
+
+func (e *ErrFanoutConflict) Error() string {
+	return "ErrFanoutConflict: routes=[" + strings.Join(e.RouteIdents, ", ") + "]: " + e.Message
+}
+
+// ErrUnreachableRoute indicate route which cannot be reached by any fork of fanout expansion.
+type ErrUnreachableRoute struct {
+	RouteIdent string
+	Message    string
+}
+
+func newErrUnreachableRoute(symbol FanoutSymbol, message string) error {
+	return &ErrUnreachableRoute{
+		RouteIdent: symbol.Fanout.Route.Ident,
+		Message:    message,
+	}
+}
+
+func (e *ErrUnreachableRoute) Error() string {
+	return "ErrUnreachableRoute: route=" + e.RouteIdent + ": " + e.Message
+}
+
+// ErrFormatCode indicate generated code cannot be formatted with go/format.
+type ErrFormatCode struct {
+	Err error
+}
+
+func (e *ErrFormatCode) Error() string {
+	return "ErrFormatCode: " + e.Err.Error()
+}
+
+// ErrRouterConflict indicate routers which cannot be generated into the same package or file.
+type ErrRouterConflict struct {
+	Option  string
+	Value1  string
+	Value2  string
+	Message string
+}
+
+func (e *ErrRouterConflict) Error() string {
+	return "ErrRouterConflict: option \"" + e.Option + "\" of routers [" + e.Value1 + "] and [" + e.Value2 + "]: " + e.Message
+}
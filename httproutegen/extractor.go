@@ -0,0 +1,178 @@
+package httproutegen
+
+import (
+	"fmt"
+)
+
+// sequenceExtractorKind is the kind of extract function for a sequence.
+type sequenceExtractorKind int
+
+const (
+	extractorUnknown sequenceExtractorKind = iota
+	extractorStringBuiltInR01NoSlash
+	extractorIntBuiltInR01
+	extractorUIntBuiltInR02
+	extractorHexIntBuiltInR03
+	extractorByteSliceStringBitMasked
+)
+
+func classifySequenceExtractor(seqPart *SequencePart) sequenceExtractorKind {
+	b0, b1 := seqPart.ByteMap.ByteMap()
+	varType := seqPart.VariableType
+	varConverter := seqPart.Converter
+	if "" != varConverter {
+		return extractorUnknown
+	}
+	switch {
+	case (0xFFFF7FFF00000000 == b0) && (0x7FFFFFFFFFFFFFFF == b1) && (varType == "string"):
+		return extractorStringBuiltInR01NoSlash
+	case (0x3FF200000000000 == b0) && (0x00000000 == b1):
+		switch varType {
+		case "int32", "int64":
+			return extractorIntBuiltInR01
+		}
+	case (0x3FF000000000000 == b0) && (0x00000000 == b1):
+		switch varType {
+		case "int32", "uint32", "int64", "uint64":
+			return extractorUIntBuiltInR02
+		}
+	case (0x3FF000000000000 == b0) && (0x7E0000007E == b1):
+		switch varType {
+		case "int32", "uint32", "int64", "uint64":
+			return extractorHexIntBuiltInR03
+		}
+	case (varType == "string") || (varType == "[]byte"):
+		return extractorByteSliceStringBitMasked
+	}
+	return extractorUnknown
+}
+
+// computeByteSliceStringBitMask get range base and bit mask pages for bit-masked extract function.
+func computeByteSliceStringBitMask(seqPart *SequencePart) (rangeBase byte, bitmaskSlice []uint32) {
+	rangeBase = 0xFF
+	for bidx := byte(0); bidx < 128; bidx++ {
+		if seqPart.ByteMap.HasByte(bidx) && rangeBase == 0xFF {
+			rangeBase = bidx
+			break
+		}
+	}
+	bitmaskSlice = make([]uint32, 4)
+	for bidx := byte(0); bidx < 128; bidx++ {
+		if (bidx < rangeBase) || (!seqPart.ByteMap.HasByte(bidx)) {
+			continue
+		}
+		v := bidx - rangeBase
+		page := (v >> 5) & 0x3
+		nbit := v & 0x1F
+		bitmaskSlice[page] = bitmaskSlice[page] | (1 << nbit)
+	}
+	return
+}
+
+// typeTitleOfIntegerType return title used in name of extract function for given integer type.
+func typeTitleOfIntegerType(varType string) string {
+	switch varType {
+	case "int32":
+		return "Int32"
+	case "uint32":
+		return "UInt32"
+	case "int64":
+		return "Int64"
+	case "uint64":
+		return "UInt64"
+	}
+	return ""
+}
+
+func makeIntegerParameterValue(varType string, result uint64, negative bool) interface{} {
+	switch varType {
+	case "int32":
+		v := int32(uint32(result))
+		if negative {
+			v = -v
+		}
+		return v
+	case "uint32":
+		return uint32(result)
+	case "int64":
+		v := int64(result)
+		if negative {
+			v = -v
+		}
+		return v
+	}
+	return result
+}
+
+var filterMaskHexIntBuiltInR03 = [...]uint16{0x7E, 0, 0x7E, 0x3FF}
+var offsetValueHexIntBuiltInR03 = [...]byte{9, 0, 9, 0}
+
+// extractSequenceValue extract value of sequence in the same way as generated extract function.
+func extractSequenceValue(seqPart *SequencePart, v string, offset, bound int) (value interface{}, nextOffset int, err error) {
+	kind := classifySequenceExtractor(seqPart)
+	if (offset > bound) && ((kind == extractorStringBuiltInR01NoSlash) || (kind == extractorByteSliceStringBitMasked)) {
+		// generated extract function skip loop and report bound as next offset.
+		offset = bound
+	}
+	switch kind {
+	case extractorStringBuiltInR01NoSlash:
+		idx := offset
+		for ; idx < bound; idx++ {
+			if v[idx] == '/' {
+				break
+			}
+		}
+		return v[offset:idx], idx, nil
+	case extractorIntBuiltInR01, extractorUIntBuiltInR02:
+		if bound <= offset {
+			return makeIntegerParameterValue(seqPart.VariableType, 0, false), offset, ErrFragmentSmallerThanExpect
+		}
+		negative := false
+		if (kind == extractorIntBuiltInR01) && (v[offset] == '-') {
+			negative = true
+			offset++
+		}
+		var result uint64
+		idx := offset
+		for ; idx < bound; idx++ {
+			ch := v[idx]
+			if (ch < '0') || (ch > '9') {
+				break
+			}
+			result = result*10 + uint64(ch&0x0F)
+		}
+		return makeIntegerParameterValue(seqPart.VariableType, result, negative), idx, nil
+	case extractorHexIntBuiltInR03:
+		if bound <= offset {
+			return makeIntegerParameterValue(seqPart.VariableType, 0, false), offset, ErrFragmentSmallerThanExpect
+		}
+		var result uint64
+		idx := offset
+		for ; idx < bound; idx++ {
+			ch := v[idx]
+			digit := (ch & 0x0F)
+			page := ((ch >> 4) & 0x3)
+			if (filterMaskHexIntBuiltInR03[page] & (1 << digit)) == 0 {
+				break
+			}
+			result = result<<4 | uint64(digit+offsetValueHexIntBuiltInR03[page])
+		}
+		return makeIntegerParameterValue(seqPart.VariableType, result, false), idx, nil
+	case extractorByteSliceStringBitMasked:
+		rangeBase, bitmaskSlice := computeByteSliceStringBitMask(seqPart)
+		idx := offset
+		for ; idx < bound; idx++ {
+			moved := v[idx] - rangeBase
+			page := (moved >> 5) & 0x3
+			nbit := moved & 0x1F
+			if 0 == (bitmaskSlice[page] & (1 << nbit)) {
+				break
+			}
+		}
+		if seqPart.VariableType == "[]byte" {
+			return []byte(v[offset:idx]), idx, nil
+		}
+		return v[offset:idx], idx, nil
+	}
+	return nil, offset, fmt.Errorf("no extract function for sequence: type=%v, converter=%v", seqPart.VariableType, seqPart.Converter)
+}
@@ -2,7 +2,7 @@ package httproutegen
 
 import (
 	"fmt"
-	"log"
+	"strconv"
 	"strings"
 )
 
@@ -40,7 +40,7 @@ type FanoutEntry struct {
 
 // MakeFanoutEntry maps given RouteEntry and sub-route entries to FanoutEntry.
 func MakeFanoutEntry(symbolScope *SymbolScope, routeEntry *RouteEntry) (fanoutEntry *FanoutEntry, err error) {
-	symbols, err := symbolScope.ParseComponent([]byte(routeEntry.Component))
+	symbols, err := symbolScope.parseComponentOf(routeEntry.Ident, []byte(routeEntry.Component))
 	if nil != err {
 		err = newErrParseComponent(routeEntry.Ident, err)
 		return
@@ -72,7 +72,7 @@ func (entry *FanoutEntry) updateMatchSymbolDepth(symbolScope *SymbolScope, headi
 		entry.MatchSymbolDepthFinish = headingSymbolDepth + len(entry.Symbols) - 1
 	} else if ("" != entry.Route.StrictPrefixMatch) &&
 		strings.HasPrefix(entry.Route.Component, entry.Route.StrictPrefixMatch) {
-		symbols, err := symbolScope.ParseComponent([]byte(entry.Route.StrictPrefixMatch))
+		symbols, err := symbolScope.parseComponentOf(entry.Route.Ident, []byte(entry.Route.StrictPrefixMatch))
 		if nil != err {
 			return newErrParseComponent(entry.Route.Ident+"::StrictPrefixMatch", err)
 		}
@@ -146,9 +146,9 @@ func (entry *FanoutEntry) AssignSerial(serialFrom int32) int32 {
 }
 
 // GetSymbol return symbols in given depth.
+// Empty result is returned for negative depth which cannot be reach.
 func (entry *FanoutEntry) GetSymbol(depth int) (result []FanoutSymbol) {
 	if depth < 0 {
-		log.Printf("WARN: request symbol at %d which cannot be reach.", depth)
 		return
 	}
 	if len(entry.Symbols) > depth {
@@ -199,7 +199,7 @@ func isTerminateSerialsCoveredFanoutSymbol(terminateSerials []int32, symbol Fano
 // FanoutLiteralDigestSet is a group of fanouts share same literal digest value.
 type FanoutLiteralDigestSet struct {
 	TerminateSerials []int32
-	Value            uint32
+	Value            uint64
 }
 
 // Covered check if given symbol is covered in this digest set
@@ -231,11 +231,11 @@ func (p *FanoutLiteralDigestPartition) searchDigestSet(symbol FanoutSymbol) int
 func (p *FanoutLiteralDigestPartition) FeedSymbols(symbols []FanoutSymbol) {
 	var updatedSet []*FanoutLiteralDigestSet
 	for _, sym := range symbols {
-		var digestValue uint32
+		var digestValue uint64
 		if dstIdx := p.searchDigestSet(sym); dstIdx < 0 {
-			digestValue = uint32(sym.Symbol.ByteValue)
+			digestValue = uint64(sym.Symbol.ByteValue)
 		} else {
-			digestValue = (p.Digests[dstIdx].Value << 8) | uint32(sym.Symbol.ByteValue)
+			digestValue = (p.Digests[dstIdx].Value << 8) | uint64(sym.Symbol.ByteValue)
 		}
 		attached := false
 		for _, s := range updatedSet {
@@ -366,6 +366,22 @@ const (
 	LogicTypeInvokeHandler
 )
 
+func (t FanoutForkLogicType) String() string {
+	switch t {
+	case LogicTypeUnknown:
+		return "Unknown"
+	case LogicTypePrefixMatching:
+		return "PrefixMatching"
+	case LogicTypeFuzzyMatching:
+		return "FuzzyMatching"
+	case LogicTypeGetParameter:
+		return "GetParameter"
+	case LogicTypeInvokeHandler:
+		return "InvokeHandler"
+	}
+	return "FanoutForkLogicType(" + strconv.FormatInt(int64(t), 10) + ")"
+}
+
 // FanoutFork track status of an expanding branch of fanout.
 type FanoutFork struct {
 	CoveredTerminals []int32             `json:"convered_terminals"`
@@ -379,6 +395,10 @@ type FanoutFork struct {
 	MaxMatchingDepth     int `json:"max_matching_depth,omitempty"`
 	PrefixLiteralDigests FanoutLiteralDigestPartition
 
+	// PrefixFallbackDigests is the first step of prefix matching merged into this fork.
+	// It is compared when remaining path is shorter than PrefixLiteralDigests.Depth.
+	PrefixFallbackDigests *FanoutLiteralDigestPartition `json:"prefix_fallback_digests,omitempty"`
+
 	FuzzyTracker FanoutFuzzyTrackPartition
 	FuzzyModeBit int `json:"fuzzy_mode_bit,omitempty"`
 
@@ -387,6 +407,8 @@ type FanoutFork struct {
 	AvailableSequenceVarName []string `json:"available_sequence_variable,omitempty"`
 
 	InvokeHandlerFanout *FanoutEntry `json:"invoke_handler,omitempty"`
+
+	diagnostics Diagnostics
 }
 
 // IsTipAreaFork return if this fork is tip of current area fork.
@@ -433,7 +455,7 @@ func (fork *FanoutFork) Covered(fanoutSymbol FanoutSymbol) bool {
 func (fork *FanoutFork) FullyMatch(fanoutSymbol FanoutSymbol) bool {
 	termSerials := fanoutSymbol.Fanout.GetTerminateSerials()
 	if len(fork.CoveredTerminals) != len(termSerials) {
-		log.Printf("INFO: terminate serial not fully match: %v vs. %v", fork.CoveredTerminals, termSerials)
+		fork.reportNotFullyMatch(fanoutSymbol, termSerials)
 		return false
 	}
 	for _, serial := range fork.CoveredTerminals {
@@ -445,13 +467,18 @@ func (fork *FanoutFork) FullyMatch(fanoutSymbol FanoutSymbol) bool {
 			}
 		}
 		if !found {
-			log.Printf("INFO: terminate serial not fully match: %v vs. %v", fork.CoveredTerminals, termSerials)
+			fork.reportNotFullyMatch(fanoutSymbol, termSerials)
 			return false
 		}
 	}
 	return true
 }
 
+func (fork *FanoutFork) reportNotFullyMatch(fanoutSymbol FanoutSymbol, termSerials []int32) {
+	reportDiagnostic(fork.diagnostics, DiagnosticInfo, DiagnosticTerminateSerialNotFullyMatch, fanoutSymbol.Fanout.Route.Ident,
+		"terminate serial not fully match: %v vs. %v", fork.CoveredTerminals, termSerials)
+}
+
 func (fork *FanoutFork) haveCoveredTerminateSerials(terminalSerial []int32) bool {
 	for _, ts := range fork.CoveredTerminals {
 		for _, oth := range terminalSerial {
@@ -473,7 +500,7 @@ func (fork *FanoutFork) FindChildForkViaTerminateSerials(terminalSerial []int32)
 	return
 }
 
-func (fork *FanoutFork) chooseLogicType(symbols []FanoutSymbol, symbolDepth int) FanoutForkLogicType {
+func (fork *FanoutFork) chooseLogicType(symbols []FanoutSymbol, symbolDepth int) (FanoutForkLogicType, error) {
 	maxMatchingDepth := 0
 	symbolType := SymbolTypeNoop
 	for _, sym := range symbols {
@@ -481,7 +508,7 @@ func (fork *FanoutFork) chooseLogicType(symbols []FanoutSymbol, symbolDepth int)
 			if symbolType == SymbolTypeNoop {
 				symbolType = sym.Symbol.Type
 			} else {
-				log.Fatalf("mixed symbol type: %#v", symbols)
+				return LogicTypeUnknown, newErrFanoutConflict(symbols, "mixed symbol type at depth "+strconv.Itoa(symbolDepth))
 			}
 		}
 		if (maxMatchingDepth < sym.Fanout.MatchSymbolDepthFinish) && sym.Fanout.WithinMatchingDepthRange(symbolDepth) {
@@ -490,26 +517,27 @@ func (fork *FanoutFork) chooseLogicType(symbols []FanoutSymbol, symbolDepth int)
 	}
 	switch symbolType {
 	case SymbolTypeNoop:
-		log.Fatalf("not reaching usable logic type: %#v", symbols)
+		return LogicTypeUnknown, newErrFanoutConflict(symbols, "not reaching usable logic type at depth "+strconv.Itoa(symbolDepth))
 	case SymbolTypeByte:
 		if maxMatchingDepth > 0 {
 			fork.MaxMatchingDepth = maxMatchingDepth
-			return LogicTypePrefixMatching
+			return LogicTypePrefixMatching, nil
 		}
 	case SymbolTypeSequence:
-		return LogicTypeGetParameter
+		return LogicTypeGetParameter, nil
 	}
 	if len(symbols) == 1 {
-		return LogicTypeUnknown
+		return LogicTypeUnknown, nil
 	}
-	return LogicTypeFuzzyMatching
+	return LogicTypeFuzzyMatching, nil
 }
 
 func (fork *FanoutFork) makeNextStageForksFromPrefixMatching() (nextStageForks []*FanoutFork) {
 	for _, s := range fork.PrefixLiteralDigests.Digests {
 		aux := FanoutFork{
-			BaseOffset: 0, // fork.BaseOffset + fork.PrefixLiteralDigests.Depth,
-			AreaName:   fork.AreaName,
+			BaseOffset:  0, // fork.BaseOffset + fork.PrefixLiteralDigests.Depth,
+			AreaName:    fork.AreaName,
+			diagnostics: fork.diagnostics,
 		}
 		aux.CoveredTerminals = append(aux.CoveredTerminals, s.TerminateSerials...)
 		aux.AvailableSequenceVarName = append(aux.AvailableSequenceVarName, fork.AvailableSequenceVarName...)
@@ -524,9 +552,12 @@ func (fork *FanoutFork) rejectSymbolWithSealPrefixMatching(symbols []FanoutSymbo
 	for _, sym := range symbols {
 		switch sym.Symbol.Type {
 		case SymbolTypeSequence:
-			fo := FindFanoutForkForSymbol(nextStageForks, sym)
+			fo, err := FindFanoutForkForSymbol(nextStageForks, sym)
+			if nil != err {
+				return true, nil, err
+			}
 			if !fo.FullyMatch(sym) {
-				err = fmt.Errorf("parameter fetch is not fully matching fork: %#v, %v", sym.Fanout, fo.LogicType)
+				return true, nil, newErrFanoutConflict(fo.coveredSymbols(symbols), "parameter fetch is not fully matching fork")
 			}
 		}
 	}
@@ -542,11 +573,13 @@ func (fork *FanoutFork) feedSymbolsToPrefixMatching(symbols []FanoutSymbol, symb
 		totalCoveredTerminals += len(sym.Fanout.GetTerminateSerials())
 	}
 	if totalCoveredTerminals < fork.PrefixLiteralDigests.CoveredTerminalCount() {
-		log.Printf("INFO: feedSymbolsToPrefixMatching - covered terminal count shrink: %d <- %d", totalCoveredTerminals, fork.PrefixLiteralDigests.CoveredTerminalCount())
+		reportDiagnostic(fork.diagnostics, DiagnosticInfo, DiagnosticPrefixMatchingCoverageShrink,
+			strings.Join(routeIdentsOfSymbols(symbols), ", "),
+			"covered terminal count shrink at depth %d: %d <- %d", symbolDepth, totalCoveredTerminals, fork.PrefixLiteralDigests.CoveredTerminalCount())
 		return fork.rejectSymbolWithSealPrefixMatching(symbols)
 	}
 	fork.PrefixLiteralDigests.FeedSymbols(symbols)
-	if (fork.MaxMatchingDepth == symbolDepth) || (fork.PrefixLiteralDigests.Depth == 4) {
+	if (fork.MaxMatchingDepth == symbolDepth) || (fork.PrefixLiteralDigests.Depth == PrefixDigestLimit32) {
 		return false, fork.makeNextStageForksFromPrefixMatching(), nil
 	}
 	return false, nil, nil
@@ -571,8 +604,9 @@ func (fork *FanoutFork) makeNextStageForksFromFuzzyMatching() (nextStageForks []
 	}
 	for _, s := range trackSet {
 		aux := FanoutFork{
-			BaseOffset: fork.FuzzyTracker.Depth - trackDepth, // fork.BaseOffset + fork.FuzzyTracker.Depth,
-			AreaName:   fork.AreaName,
+			BaseOffset:  fork.FuzzyTracker.Depth - trackDepth, // fork.BaseOffset + fork.FuzzyTracker.Depth,
+			AreaName:    fork.AreaName,
+			diagnostics: fork.diagnostics,
 		}
 		aux.CoveredTerminals = append(aux.CoveredTerminals, s.TerminateSerials...)
 		aux.AvailableSequenceVarName = append(aux.AvailableSequenceVarName, fork.AvailableSequenceVarName...)
@@ -587,9 +621,12 @@ func (fork *FanoutFork) rejectSymbolWithSealFuzzyMatching(symbols []FanoutSymbol
 	for _, sym := range symbols {
 		switch sym.Symbol.Type {
 		case SymbolTypeSequence:
-			fo := FindFanoutForkForSymbol(nextStageForks, sym)
+			fo, err := FindFanoutForkForSymbol(nextStageForks, sym)
+			if nil != err {
+				return true, nil, err
+			}
 			if !fo.FullyMatch(sym) {
-				err = fmt.Errorf("parameter fetch is not fully matching fork: %#v, %v", sym.Fanout, fo.LogicType)
+				return true, nil, newErrFanoutConflict(fo.coveredSymbols(symbols), "parameter fetch is not fully matching fork")
 			}
 		}
 	}
@@ -605,7 +642,9 @@ func (fork *FanoutFork) feedSymbolsToFuzzyMatching(symbols []FanoutSymbol, symbo
 		totalCoveredTerminals += len(sym.Fanout.GetTerminateSerials())
 	}
 	if totalCoveredTerminals < fork.FuzzyTracker.CoveredTerminalCount() {
-		log.Printf("INFO: feedSymbolsToFuzzyMatching - covered terminal count shrink: %d <- %d", totalCoveredTerminals, fork.FuzzyTracker.CoveredTerminalCount())
+		reportDiagnostic(fork.diagnostics, DiagnosticInfo, DiagnosticFuzzyMatchingCoverageShrink,
+			strings.Join(routeIdentsOfSymbols(symbols), ", "),
+			"covered terminal count shrink at depth %d: %d <- %d", symbolDepth, totalCoveredTerminals, fork.FuzzyTracker.CoveredTerminalCount())
 		return fork.rejectSymbolWithSealFuzzyMatching(symbols)
 	}
 	fork.FuzzyTracker.FeedSymbols(symbols)
@@ -616,6 +655,7 @@ func (fork *FanoutFork) makeNextStageForksFromGetParameter() (nextStageForks []*
 	aux := FanoutFork{
 		CoveredTerminals: fork.CoveredTerminals,
 		AreaName:         fork.AreaName,
+		diagnostics:      fork.diagnostics,
 	}
 	aux.AvailableSequenceVarName = append(aux.AvailableSequenceVarName, fork.AvailableSequenceVarName...)
 	nextStageForks = append(nextStageForks, &aux)
@@ -625,23 +665,23 @@ func (fork *FanoutFork) makeNextStageForksFromGetParameter() (nextStageForks []*
 func (fork *FanoutFork) feedSymbolsToGetParameter(symbols []FanoutSymbol) (reject bool, nextStageForks []*FanoutFork, err error) {
 	for _, sym := range symbols {
 		if sym.Symbol.Type != SymbolTypeSequence {
-			log.Fatalf("should be sequence symbol: %#v", sym.Symbol)
+			return true, nil, newErrFanoutConflict(symbols, "mixed parameter and literal symbols")
 		}
 		if fork.SequenceVarName == "" {
 			for _, existedVarName := range fork.AvailableSequenceVarName {
 				if existedVarName == sym.Symbol.SequenceVarName {
-					return true, nil, fmt.Errorf("sequence variable name existed: %v, %v", fork.AvailableSequenceVarName, sym.Symbol.SequenceVarName)
+					return true, nil, newErrFanoutConflict(symbols, "sequence variable name existed: "+sym.Symbol.SequenceVarName)
 				}
 			}
 			fork.SequenceIndex = sym.Symbol.SequenceIndex
 			fork.SequenceVarName = sym.Symbol.SequenceVarName
 			fork.AvailableSequenceVarName = append(fork.AvailableSequenceVarName, sym.Symbol.SequenceVarName)
 		} else if (fork.SequenceIndex != sym.Symbol.SequenceIndex) || (fork.SequenceVarName != sym.Symbol.SequenceVarName) {
-			return true, nil, fmt.Errorf("incompatible sequence: (%v, %v) != %#v", fork.SequenceIndex, fork.SequenceVarName, sym)
+			return true, nil, newErrFanoutConflict(symbols, "incompatible sequence: "+fork.SequenceVarName+" vs. "+sym.Symbol.SequenceVarName)
 		}
 	}
 	if fork.SequenceVarName == "" {
-		return true, nil, fmt.Errorf("empty sequence variable name: %#v", symbols)
+		return true, nil, newErrFanoutConflict(symbols, "empty sequence variable name")
 	}
 	return false, fork.makeNextStageForksFromGetParameter(), nil
 }
@@ -649,7 +689,9 @@ func (fork *FanoutFork) feedSymbolsToGetParameter(symbols []FanoutSymbol) (rejec
 // FeedSymbols get symbols from fanouts and update logic state for code generation.
 func (fork *FanoutFork) FeedSymbols(symbols []FanoutSymbol, symbolDepth int) (reject bool, nextStageForks []*FanoutFork, err error) {
 	if LogicTypeUnknown == fork.LogicType {
-		fork.LogicType = fork.chooseLogicType(symbols, symbolDepth)
+		if fork.LogicType, err = fork.chooseLogicType(symbols, symbolDepth); nil != err {
+			return true, nil, err
+		}
 	}
 	switch fork.LogicType {
 	case LogicTypePrefixMatching:
@@ -677,12 +719,21 @@ func (fork *FanoutFork) divideThisFork() (hasDivide bool) {
 	return false
 }
 
-func (fork *FanoutFork) sealThisFork(rootFanoutEntry *FanoutEntry) (stopPropagate bool) {
+func (fork *FanoutFork) sealThisFork(rootFanoutEntry *FanoutEntry) (stopPropagate bool, err error) {
 	if len(fork.CoveredTerminals) != 1 {
 		if !fork.divideThisFork() {
-			log.Fatalf("ERROR: does not terminate with one and only one terminate serial: %#v", fork)
+			var routeIdents []string
+			for _, serial := range fork.CoveredTerminals {
+				if fanoutEntry := rootFanoutEntry.FindFanoutEntryBySerial(serial); nil != fanoutEntry {
+					routeIdents = append(routeIdents, fanoutEntry.Route.Ident)
+				}
+			}
+			return false, &ErrFanoutConflict{
+				RouteIdents: routeIdents,
+				Message:     "does not terminate with one and only one route",
+			}
 		}
-		return false
+		return false, nil
 	}
 	handlerFanout := rootFanoutEntry.FindFanoutEntryBySerial(fork.CoveredTerminals[0])
 	if fork.LogicType == LogicTypeUnknown {
@@ -695,34 +746,65 @@ func (fork *FanoutFork) sealThisFork(rootFanoutEntry *FanoutEntry) (stopPropagat
 			CoveredTerminals:    fork.CoveredTerminals,
 			ParentFork:          fork,
 			InvokeHandlerFanout: handlerFanout,
+			diagnostics:         fork.diagnostics,
 		}
 		aux.AvailableSequenceVarName = append(aux.AvailableSequenceVarName, fork.AvailableSequenceVarName...)
 		fork.ChildForks = []*FanoutFork{&aux}
 	}
-	return true
+	return true, nil
 }
 
 // SealTerminateFork mark or create invoke fork for terminate fork.
-func (fork *FanoutFork) SealTerminateFork(rootFanoutEntry *FanoutEntry) {
+func (fork *FanoutFork) SealTerminateFork(rootFanoutEntry *FanoutEntry) (err error) {
 	if len(fork.ChildForks) == 0 {
-		if fork.sealThisFork(rootFanoutEntry) {
-			return
+		stopPropagate, err := fork.sealThisFork(rootFanoutEntry)
+		if (nil != err) || stopPropagate {
+			return err
 		}
 	}
 	for _, childFork := range fork.ChildForks {
-		childFork.SealTerminateFork(rootFanoutEntry)
+		if err = childFork.SealTerminateFork(rootFanoutEntry); nil != err {
+			return
+		}
+	}
+	return nil
+}
+
+// coveredSymbols return symbols which are covered by this fork.
+func (fork *FanoutFork) coveredSymbols(symbols []FanoutSymbol) (result []FanoutSymbol) {
+	for _, sym := range symbols {
+		if fork.Covered(sym) {
+			result = append(result, sym)
+		}
 	}
+	return
+}
+
+// routeIdentsOfSymbols collect distinct route idents of given symbols in order.
+func routeIdentsOfSymbols(symbols []FanoutSymbol) (routeIdents []string) {
+	for _, sym := range symbols {
+		found := false
+		for _, ident := range routeIdents {
+			if ident == sym.Fanout.Route.Ident {
+				found = true
+				break
+			}
+		}
+		if !found {
+			routeIdents = append(routeIdents, sym.Fanout.Route.Ident)
+		}
+	}
+	return
 }
 
 // FindFanoutForkForSymbol search for FanoutFork via symbol coverage.
-func FindFanoutForkForSymbol(forks []*FanoutFork, symbol FanoutSymbol) *FanoutFork {
+func FindFanoutForkForSymbol(forks []*FanoutFork, symbol FanoutSymbol) (*FanoutFork, error) {
 	for _, fo := range forks {
 		if fo.Covered(symbol) {
-			return fo
+			return fo, nil
 		}
 	}
-	log.Fatalf("ERROR: cannot reach fork for symbol: %#v, from %v", symbol, forks)
-	return nil
+	return nil, newErrUnreachableRoute(symbol, "cannot reach fork for symbol")
 }
 
 // FanoutForkSlice package operations for series of FanoutForks
@@ -740,14 +822,19 @@ func (s *FanoutForkSlice) AttachParentFork(fork *FanoutFork) {
 			continue
 		}
 		if fo.ParentFork != nil {
-			log.Printf("parent fork existed: %v", fo)
+			var routeIdent string
+			if nil != fo.InvokeHandlerFanout {
+				routeIdent = fo.InvokeHandlerFanout.Route.Ident
+			}
+			reportDiagnostic(fork.diagnostics, DiagnosticWarning, DiagnosticParentForkExisted, routeIdent,
+				"parent fork existed: %v", fo)
 		}
 		fo.ParentFork = fork
 		fork.ChildForks = append(fork.ChildForks, fo)
 	}
 }
 
-func (s *FanoutForkSlice) distributeSymbols(symbols []FanoutSymbol) [][]FanoutSymbol {
+func (s *FanoutForkSlice) distributeSymbols(symbols []FanoutSymbol) ([][]FanoutSymbol, error) {
 	symbolBuckets := make([][]FanoutSymbol, len(s.Forks))
 	for _, sym := range symbols {
 		emitted := false
@@ -760,15 +847,18 @@ func (s *FanoutForkSlice) distributeSymbols(symbols []FanoutSymbol) [][]FanoutSy
 			break
 		}
 		if !emitted {
-			log.Fatalf("ERROR: symbol failed to emit into fork bucket: %#v", sym)
+			return nil, newErrUnreachableRoute(sym, "symbol failed to emit into fork bucket")
 		}
 	}
-	return symbolBuckets
+	return symbolBuckets, nil
 }
 
 // AssignAreaName set area name if symbols for fork is not divergent.
-func (s *FanoutForkSlice) AssignAreaName(symbols []FanoutSymbol) {
-	symbolBuckets := s.distributeSymbols(symbols)
+func (s *FanoutForkSlice) AssignAreaName(symbols []FanoutSymbol) error {
+	symbolBuckets, err := s.distributeSymbols(symbols)
+	if nil != err {
+		return err
+	}
 	for idx, fanout := range s.Forks {
 		if len(symbolBuckets[idx]) == 0 {
 			continue
@@ -779,12 +869,16 @@ func (s *FanoutForkSlice) AssignAreaName(symbols []FanoutSymbol) {
 		}
 		fanout.AreaName = areaName
 	}
+	return nil
 }
 
 // FeedSymbols feed symbols into covered FanoutFork.
 // The slice will be update if fork is forked further.
 func (s *FanoutForkSlice) FeedSymbols(symbols []FanoutSymbol, symbolDepth int) error {
-	symbolBuckets := s.distributeSymbols(symbols)
+	symbolBuckets, err := s.distributeSymbols(symbols)
+	if nil != err {
+		return err
+	}
 	var updatedForks []*FanoutFork
 	for idx, fanout := range s.Forks {
 		if len(symbolBuckets[idx]) == 0 {
@@ -803,11 +897,13 @@ func (s *FanoutForkSlice) FeedSymbols(symbols []FanoutSymbol, symbolDepth int) e
 					return err
 				}
 			}
-			subSlice.AssignAreaName(symbolBuckets[idx])
+			if err = subSlice.AssignAreaName(symbolBuckets[idx]); nil != err {
+				return err
+			}
 			updatedForks = append(updatedForks, subSlice.Forks...)
 		} else {
 			if reject {
-				log.Fatalf("ERROR: rejected FeedSymbols() must return new forks: %#v; [PARENT]: %#v", fanout, fanout.ParentFork)
+				return newErrFanoutConflict(symbolBuckets[idx], "rejected symbols without new forks")
 			}
 			updatedForks = append(updatedForks, fanout)
 		}
@@ -821,12 +917,28 @@ type FanoutInstance struct {
 	InstanceSymbolScope SymbolScope  `json:"symbol_scope"`
 	RootFanoutEntry     *FanoutEntry `json:"root_fanout"`
 
-	RootFanoutFork *FanoutFork `json:"root_fork"`
+	RootFanoutFork    *FanoutFork `json:"root_fork"`
+	PrefixDigestLimit int         `json:"prefix_digest_limit"`
+
+	// DisablePrefixDigest64 keep prefix matching in steps of up to 4 bytes.
+	DisablePrefixDigest64 bool `json:"-"`
+
+	diagnostics Diagnostics
 }
 
 // MakeFanoutInstance creates new fanout operation instance from root route entry.
 func MakeFanoutInstance(rootRouteEntry *RouteEntry) (instance *FanoutInstance, err error) {
-	instance = &FanoutInstance{}
+	return MakeFanoutInstanceWithDiagnostics(rootRouteEntry, nil)
+}
+
+// MakeFanoutInstanceWithDiagnostics creates new fanout operation instance from root route entry.
+// Diagnostics of parsing and expansion are reported to given diagnostics,
+// or standard logger if nil.
+func MakeFanoutInstanceWithDiagnostics(rootRouteEntry *RouteEntry, diagnostics Diagnostics) (instance *FanoutInstance, err error) {
+	instance = &FanoutInstance{
+		diagnostics: diagnostics,
+	}
+	instance.InstanceSymbolScope.diagnostics = diagnostics
 	if instance.RootFanoutEntry, err = MakeFanoutEntry(&instance.InstanceSymbolScope, rootRouteEntry); nil != err {
 		return nil, err
 	}
@@ -836,11 +948,93 @@ func MakeFanoutInstance(rootRouteEntry *RouteEntry) (instance *FanoutInstance, e
 	return
 }
 
+// Limits of literal bytes compared in one step of prefix matching.
+const (
+	PrefixDigestLimit32 = 4
+	PrefixDigestLimit64 = 8
+)
+
+// mergeablePrefixMatchingChildForks return the child fork of each digest set
+// if they can be merged into this prefix matching fork as one digest64 step.
+// Child forks must be prefix matching forks of the same area which start right
+// after this fork with the same digest length, so merged step does not span
+// an area or area tip boundary.
+func (fork *FanoutFork) mergeablePrefixMatchingChildForks() (childForks []*FanoutFork) {
+	if (fork.LogicType != LogicTypePrefixMatching) || (nil != fork.PrefixFallbackDigests) {
+		return nil
+	}
+	childDepth := 0
+	for _, digestSet := range fork.PrefixLiteralDigests.Digests {
+		matchedForks := fork.FindChildForkViaTerminateSerials(digestSet.TerminateSerials)
+		if len(matchedForks) != 1 {
+			return nil
+		}
+		childFork := matchedForks[0]
+		if (childFork.LogicType != LogicTypePrefixMatching) || (childFork.BaseOffset != 0) ||
+			(childFork.AreaName != fork.AreaName) || (nil != childFork.PrefixFallbackDigests) ||
+			(len(childFork.CoveredTerminals) != len(digestSet.TerminateSerials)) {
+			return nil
+		}
+		if 0 == childDepth {
+			childDepth = childFork.PrefixLiteralDigests.Depth
+		} else if childDepth != childFork.PrefixLiteralDigests.Depth {
+			return nil
+		}
+		childForks = append(childForks, childFork)
+	}
+	if depth := fork.PrefixLiteralDigests.Depth + childDepth; (depth <= PrefixDigestLimit32) || (depth > PrefixDigestLimit64) {
+		return nil
+	}
+	return
+}
+
+// mergePrefixMatchingForks merge prefix matching steps of forks into
+// digest64 steps. The order of digests and child forks are kept so the
+// route targets are visited in the same order as the separated steps.
+func (fork *FanoutFork) mergePrefixMatchingForks() (merged bool) {
+	if childForks := fork.mergeablePrefixMatchingChildForks(); len(childForks) > 0 {
+		fallbackDigests := fork.PrefixLiteralDigests
+		childDepth := childForks[0].PrefixLiteralDigests.Depth
+		var mergedDigests []*FanoutLiteralDigestSet
+		var grandChildForks []*FanoutFork
+		for idx, digestSet := range fallbackDigests.Digests {
+			childFork := childForks[idx]
+			for _, childDigestSet := range childFork.PrefixLiteralDigests.Digests {
+				mergedDigests = append(mergedDigests, &FanoutLiteralDigestSet{
+					TerminateSerials: childDigestSet.TerminateSerials,
+					Value:            (digestSet.Value << uint(8*childDepth)) | childDigestSet.Value,
+				})
+			}
+			for _, grandChildFork := range childFork.ChildForks {
+				grandChildFork.ParentFork = fork
+				grandChildForks = append(grandChildForks, grandChildFork)
+			}
+		}
+		fork.PrefixFallbackDigests = &fallbackDigests
+		fork.PrefixLiteralDigests = FanoutLiteralDigestPartition{
+			Digests: mergedDigests,
+			Depth:   fallbackDigests.Depth + childDepth,
+		}
+		fork.ChildForks = grandChildForks
+		merged = true
+	}
+	for _, childFork := range fork.ChildForks {
+		if childFork.mergePrefixMatchingForks() {
+			merged = true
+		}
+	}
+	return
+}
+
 // ExpandFanout expand fanout entries into fanout fork.
+// Prefix matching compares up to 4 bytes in one step (digest32). Unless
+// DisablePrefixDigest64 is set, two consecutive steps of the same area are
+// then merged into one step of up to 8 bytes (digest64).
 func (instance *FanoutInstance) ExpandFanout() (err error) {
 	rootFanoutFork := &FanoutFork{
 		CoveredTerminals: instance.RootFanoutEntry.TerminateSerials,
 		AreaName:         instance.RootFanoutEntry.Route.AreaName,
+		diagnostics:      instance.diagnostics,
 	}
 	fanoutForks := FanoutForkSlice{
 		Forks: []*FanoutFork{rootFanoutFork},
@@ -854,8 +1048,14 @@ func (instance *FanoutInstance) ExpandFanout() (err error) {
 		depth++
 		symbols = instance.RootFanoutEntry.GetSymbol(depth)
 	}
-	rootFanoutFork.SealTerminateFork(instance.RootFanoutEntry)
+	if err = rootFanoutFork.SealTerminateFork(instance.RootFanoutEntry); nil != err {
+		return
+	}
 	// rootFanoutFork.ErodeAreaName()
+	instance.PrefixDigestLimit = PrefixDigestLimit32
+	if !instance.DisablePrefixDigest64 && rootFanoutFork.mergePrefixMatchingForks() {
+		instance.PrefixDigestLimit = PrefixDigestLimit64
+	}
 	instance.RootFanoutFork = rootFanoutFork
 	return nil
 }
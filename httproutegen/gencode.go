@@ -1,16 +1,19 @@
 package httproutegen
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"log"
+	"go/format"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
-
-	rungofmt "github.com/yinyin/go-run-gofmt"
 )
 
 const generatedCodeIndicatorLine = "// Code generated by go-http-route-gen. DO NOT EDIT.\n\n"
@@ -60,9 +63,12 @@ func cleanupCodeBlock(codeText string, indent bool) string {
 // CodeGenerateInstance keep variables for generating code.
 type CodeGenerateInstance struct {
 	codeFilePath   string
-	fp             *os.File
+	codeBuf        *bytes.Buffer
 	rootFanoutFork *FanoutFork
 	symbolScope    *SymbolScope
+	diagnostics    Diagnostics
+
+	routeTargetSymbolScope SymbolScope
 
 	PackageName     string
 	ReceiverName    string
@@ -74,45 +80,146 @@ type CodeGenerateInstance struct {
 	AreaNames     []string
 	HandlerNames  []string
 
+	RouteTargets []*RouteTarget
+
 	SequenceExtractFunctionName []string
 
-	UsePrefixMatching bool
+	UsePrefixMatching   bool
+	UsePrefixMatching64 bool
 
 	NeedErrFragmentSmallerThanExpect bool
+
+	// SwitchMinWidth and BinarySearchMinWidth are the numbers of sibling
+	// branches of prefix or fuzzy matching from which switch statement or
+	// explicit binary search is generated. Zero means the default width.
+	SwitchMinWidth       int
+	BinarySearchMinWidth int
+
+	// UseSharedRuntime skip built-in helpers in generated code.
+	// The helpers must be provided by GenerateRuntimeTo() in the same package.
+	UseSharedRuntime bool
+
+	// UseRouteProgram generate fanout decision tree as byte program evaluated
+	// by a fixed interpreter instead of nested routing code.
+	UseRouteProgram bool
+
+	// SplitAreas generate subtree of each area as its own method of handler type.
+	// SplitLineLimit generate subtree over the number of lines as its own
+	// method of handler type, zero means no limit.
+	SplitAreas     bool
+	SplitLineLimit int
+
+	// CountRouteHits count routed requests of each route miss and route
+	// target ident in package-level counters with sync/atomic.
+	// TimeRouteHandlers also sum up time spent in handlers, it implies CountRouteHits.
+	CountRouteHits    bool
+	TimeRouteHandlers bool
+
+	// ObserverMethodName is the method of handler type invoked with request,
+	// route target ident and route template after routing succeeded and
+	// before the handler runs. Empty means no observer.
+	ObserverMethodName string
+
+	// UseHandlerInterface generate routing logic over an interface of handler
+	// methods and let route method forward to it, so that generated test can
+	// run the same routing logic against a recording fake of handler type.
+	UseHandlerInterface bool
+
+	IncludeFuzzTarget bool
+
+	funcScope   *routeFunctionScope
+	routeFuncs  *routeFunctionSet
+	paramScope  []routeParameter
+	inlineDepth int
 }
 
-// OpenCodeGenerateInstance create an instance of code generator
-func OpenCodeGenerateInstance(codeFilePath string, rootFanoutFork *FanoutFork, symbolScope *SymbolScope) (inst *CodeGenerateInstance, err error) {
-	fp, err := os.Create(codeFilePath)
-	if nil != err {
-		return
-	}
+// NewCodeGenerateInstance create an instance of code generator.
+// Diagnostics of code generation are reported to given diagnostics, or standard logger if nil.
+func NewCodeGenerateInstance(rootFanoutFork *FanoutFork, symbolScope *SymbolScope, diagnostics Diagnostics) (inst *CodeGenerateInstance, err error) {
 	inst = &CodeGenerateInstance{
-		codeFilePath:   codeFilePath,
-		fp:             fp,
 		rootFanoutFork: rootFanoutFork,
 		symbolScope:    symbolScope,
+		diagnostics:    diagnostics,
 	}
+	inst.routeTargetSymbolScope.diagnostics = diagnostics
 	inst.hasPrefixMatching(rootFanoutFork)
 	inst.collectAreaNames(rootFanoutFork)
 	inst.collectHandlerNames(rootFanoutFork)
-	inst.collectImportForErrors()
+	if err = inst.collectRouteTargets(rootFanoutFork); nil != err {
+		return nil, err
+	}
+	inst.sortRouteTargets()
+	inst.checkSharedHandlers()
 	inst.addImportModule("net/http", false)
+	inst.addImportModule("strconv", false)
+	return
+}
+
+// OpenCodeGenerateInstance create an instance of code generator for given code file.
+// It is kept for compatibility, code is written into the file by Generate().
+func OpenCodeGenerateInstance(codeFilePath string, rootFanoutFork *FanoutFork, symbolScope *SymbolScope) (inst *CodeGenerateInstance, err error) {
+	return OpenCodeGenerateInstanceWithDiagnostics(codeFilePath, rootFanoutFork, symbolScope, nil)
+}
+
+// OpenCodeGenerateInstanceWithDiagnostics create an instance of code generator for given code file.
+// Diagnostics of code generation are reported to given diagnostics, or standard logger if nil.
+func OpenCodeGenerateInstanceWithDiagnostics(codeFilePath string, rootFanoutFork *FanoutFork, symbolScope *SymbolScope, diagnostics Diagnostics) (inst *CodeGenerateInstance, err error) {
+	if inst, err = NewCodeGenerateInstance(rootFanoutFork, symbolScope, diagnostics); nil != err {
+		return
+	}
+	inst.codeFilePath = codeFilePath
 	return
 }
 
 // Close release allocated resources.
+// Nothing is held by code generator so it is kept for compatibility only.
 func (inst *CodeGenerateInstance) Close() (err error) {
-	fp := inst.fp
-	inst.fp = nil
-	if err = fp.Close(); nil == err {
-		err = rungofmt.RunGoFmt(inst.codeFilePath, true)
-		log.Printf("INFO: run gofmt: stopped with %v.", err)
+	return nil
+}
+
+// formatCode format given generated code with go/format.
+func formatCode(codeText []byte) (formattedCode []byte, err error) {
+	if formattedCode, err = format.Source(codeText); nil != err {
+		err = &ErrFormatCode{
+			Err: err,
+		}
 	}
 	return
 }
 
-// addImportModule must invoke before `Generate()` code.
+// writeFileAtomically write content into a temporary file in the same folder
+// and rename it to given file path. No partial file is left on error.
+// Mode of existing file is kept, new file is created with mode 0644.
+func writeFileAtomically(filePath string, content []byte) (err error) {
+	fileMode := os.FileMode(0644)
+	if fileInfo, statErr := os.Stat(filePath); nil == statErr {
+		fileMode = fileInfo.Mode().Perm()
+	}
+	fp, err := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if nil != err {
+		return
+	}
+	tmpFilePath := fp.Name()
+	if _, err = fp.Write(content); nil != err {
+		fp.Close()
+		os.Remove(tmpFilePath)
+		return
+	}
+	if err = fp.Close(); nil != err {
+		os.Remove(tmpFilePath)
+		return
+	}
+	if err = os.Chmod(tmpFilePath, fileMode); nil != err {
+		os.Remove(tmpFilePath)
+		return
+	}
+	if err = os.Rename(tmpFilePath, filePath); nil != err {
+		os.Remove(tmpFilePath)
+	}
+	return
+}
+
+// addImportModule must invoke before `GenerateTo()` code.
 func (inst *CodeGenerateInstance) addImportModule(moduleName string, escaped bool) {
 	if !escaped {
 		moduleName = strconv.Quote(moduleName)
@@ -125,7 +232,7 @@ func (inst *CodeGenerateInstance) addImportModule(moduleName string, escaped boo
 	inst.ImportModules = append(inst.ImportModules, moduleName)
 }
 
-// addAreaName must invoke before `Generate()` code.
+// addAreaName must invoke before `GenerateTo()` code.
 func (inst *CodeGenerateInstance) addAreaName(areaName string) {
 	for _, arName := range inst.AreaNames {
 		if arName == areaName {
@@ -135,7 +242,7 @@ func (inst *CodeGenerateInstance) addAreaName(areaName string) {
 	inst.AreaNames = append(inst.AreaNames, areaName)
 }
 
-// addHandlerName must invoke before `Generate()` code.
+// addHandlerName must invoke before `GenerateTo()` code.
 func (inst *CodeGenerateInstance) addHandlerName(handlerName string) {
 	for _, hndName := range inst.HandlerNames {
 		if hndName == handlerName {
@@ -154,9 +261,15 @@ func (inst *CodeGenerateInstance) validateConfiguration() (err error) {
 
 func (inst *CodeGenerateInstance) hasPrefixMatching(fanoutFork *FanoutFork) {
 	if fanoutFork.LogicType == LogicTypePrefixMatching {
-		inst.UsePrefixMatching = true
+		if fanoutFork.PrefixLiteralDigests.Depth > PrefixDigestLimit32 {
+			inst.UsePrefixMatching64 = true
+		} else {
+			inst.UsePrefixMatching = true
+		}
+		if nil != fanoutFork.PrefixFallbackDigests {
+			inst.UsePrefixMatching = true
+		}
 		inst.NeedErrFragmentSmallerThanExpect = true
-		return
 	}
 	for _, childFork := range fanoutFork.ChildForks {
 		inst.hasPrefixMatching(childFork)
@@ -175,6 +288,60 @@ func (inst *CodeGenerateInstance) collectHandlerNames(fanoutFork *FanoutFork) {
 	}
 }
 
+func (inst *CodeGenerateInstance) collectRouteTargets(fanoutFork *FanoutFork) error {
+	if fanoutFork.LogicType == LogicTypeInvokeHandler {
+		routeEntry := fanoutFork.InvokeHandlerFanout.Route
+		for _, target := range inst.RouteTargets {
+			if target.Route == routeEntry {
+				return nil
+			}
+		}
+		target, err := MakeRouteTarget(&inst.routeTargetSymbolScope, routeEntry)
+		if nil != err {
+			return err
+		}
+		inst.RouteTargets = append(inst.RouteTargets, target)
+		return nil
+	}
+	for _, childFork := range fanoutFork.ChildForks {
+		if err := inst.collectRouteTargets(childFork); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeIdentOfSequence find ident of the first route target which fetch given sequence.
+func (inst *CodeGenerateInstance) routeIdentOfSequence(seqIndex int) string {
+	for _, target := range inst.RouteTargets {
+		for _, sym := range target.Symbols {
+			if (sym.Type == SymbolTypeSequence) && (sym.SequenceIndex == seqIndex) {
+				return target.Route.Ident
+			}
+		}
+	}
+	return ""
+}
+
+// sortRouteTargets place route targets in the order of configuration.
+func (inst *CodeGenerateInstance) sortRouteTargets() {
+	serials := make(map[*RouteEntry]int32)
+	collectRouteEntrySerials(inst.rootFanoutFork, serials)
+	sort.SliceStable(inst.RouteTargets, func(i, j int) bool {
+		return serials[inst.RouteTargets[i].Route] < serials[inst.RouteTargets[j].Route]
+	})
+}
+
+func collectRouteEntrySerials(fanoutFork *FanoutFork, serials map[*RouteEntry]int32) {
+	if fanoutFork.LogicType == LogicTypeInvokeHandler {
+		serials[fanoutFork.InvokeHandlerFanout.Route] = fanoutFork.InvokeHandlerFanout.Serial
+		return
+	}
+	for _, childFork := range fanoutFork.ChildForks {
+		collectRouteEntrySerials(childFork, serials)
+	}
+}
+
 func (inst *CodeGenerateInstance) collectAreaNames(fanoutFork *FanoutFork) {
 	if fanoutFork.AreaName != "" {
 		inst.addAreaName(fanoutFork.AreaName)
@@ -187,22 +354,170 @@ func (inst *CodeGenerateInstance) collectAreaNames(fanoutFork *FanoutFork) {
 
 func (inst *CodeGenerateInstance) collectImportForErrors() {
 	switch {
-	case inst.NeedErrFragmentSmallerThanExpect:
+	case inst.NeedErrFragmentSmallerThanExpect && !inst.UseSharedRuntime:
 		inst.addImportModule("errors", false)
 	}
 }
 
-func (inst *CodeGenerateInstance) makeRouteMissingIdentName(areaName string) string {
+func makeRouteMissingIdentName(namePrefix, areaName string) string {
 	if "" == areaName {
 		return ""
 	}
-	return inst.NamePrefix + "RouteMiss" + areaName
+	return namePrefix + "RouteMiss" + areaName
 }
 
-func (inst *CodeGenerateInstance) makeRouteTargetIdentName(handlerName string) string {
+func makeRouteTargetIdentName(namePrefix, handlerName string) string {
 	hnd := []rune(handlerName)
 	hnd[0] = unicode.ToTitle(hnd[0])
-	return inst.NamePrefix + "RouteTo" + string(hnd)
+	return namePrefix + "RouteTo" + string(hnd)
+}
+
+// helperSuffix return suffix for names of runtime helpers (eg: extract functions)
+// so that routers generated with different NamePrefix can live in the same package.
+func (inst *CodeGenerateInstance) helperSuffix() string {
+	if "" == inst.NamePrefix {
+		return ""
+	}
+	prefix := []rune(inst.NamePrefix)
+	prefix[0] = unicode.ToTitle(prefix[0])
+	return string(prefix)
+}
+
+// runtimeHelperSuffix return suffix for names of built-in helpers which
+// are shared by all routers of package when UseSharedRuntime is set.
+func (inst *CodeGenerateInstance) runtimeHelperSuffix() string {
+	if inst.UseSharedRuntime {
+		return ""
+	}
+	return inst.helperSuffix()
+}
+
+func (inst *CodeGenerateInstance) makeRouteMissingIdentName(areaName string) string {
+	return makeRouteMissingIdentName(inst.NamePrefix, areaName)
+}
+
+func (inst *CodeGenerateInstance) makeRouteTargetIdentName(handlerName string) string {
+	return makeRouteTargetIdentName(inst.NamePrefix, handlerName)
+}
+
+func (inst *CodeGenerateInstance) routeIdentNames() (result []string) {
+	result = append(result,
+		inst.NamePrefix+"RouteNone",
+		inst.NamePrefix+"RouteIncomplete",
+		inst.NamePrefix+"RouteMethodNotAllowed",
+		inst.NamePrefix+"RouteError")
+	for _, areaName := range inst.AreaNames {
+		result = append(result, inst.makeRouteMissingIdentName(areaName))
+	}
+	result = append(result, inst.NamePrefix+"RouteSuccess")
+	for _, handlerName := range inst.HandlerNames {
+		result = append(result, inst.makeRouteTargetIdentName(handlerName))
+	}
+	return
+}
+
+func (inst *CodeGenerateInstance) generateRouteIdentStringMethodCode() string {
+	var caseCode string
+	for _, identName := range inst.routeIdentNames() {
+		caseCode += "case " + identName + ":\nreturn " + strconv.Quote(identName) + "\n"
+	}
+	return makeCodeMethodRouteIdentString(inst.NamePrefix, strings.TrimSuffix(caseCode, "\n"))
+}
+
+// checkSharedHandlers report handlers invoked by route targets of different templates.
+// Route ident is named after handler so Template() of such ident only gives the
+// template of the first route target.
+func (inst *CodeGenerateInstance) checkSharedHandlers() {
+	for _, handlerName := range inst.HandlerNames {
+		var templates []string
+		seen := make(map[string]bool)
+		for _, target := range inst.RouteTargets {
+			if (len(target.HandlerMethods(handlerName)) == 0) || seen[target.Template] {
+				continue
+			}
+			seen[target.Template] = true
+			templates = append(templates, target.Template)
+		}
+		if len(templates) > 1 {
+			reportDiagnostic(inst.diagnostics, DiagnosticWarning, DiagnosticHandlerSharedByRoutes, "",
+				"handler %s is invoked by routes of templates %s, Template() of %s gives the first one",
+				handlerName, strings.Join(templates, ", "), inst.makeRouteTargetIdentName(handlerName))
+		}
+	}
+}
+
+// routeTemplateOfHandler return template of the first route target invoking given handler.
+func (inst *CodeGenerateInstance) routeTemplateOfHandler(handlerName string) (string, bool) {
+	for _, target := range inst.RouteTargets {
+		if len(target.HandlerMethods(handlerName)) != 0 {
+			return target.Template, true
+		}
+	}
+	return "", false
+}
+
+// routeTemplateOfFork return template of the route target invoked by given fork.
+func (inst *CodeGenerateInstance) routeTemplateOfFork(fanoutFork *FanoutFork) (string, bool) {
+	routeEntry := fanoutFork.InvokeHandlerFanout.Route
+	for _, target := range inst.RouteTargets {
+		if target.Route == routeEntry {
+			return target.Template, true
+		}
+	}
+	return "", false
+}
+
+func (inst *CodeGenerateInstance) generateRouteIdentTemplateMethodCode() string {
+	var caseCode string
+	for _, handlerName := range inst.HandlerNames {
+		if template, ok := inst.routeTemplateOfHandler(handlerName); ok {
+			caseCode += "case " + inst.makeRouteTargetIdentName(handlerName) + ":\nreturn " + strconv.Quote(template) + "\n"
+		}
+	}
+	return makeCodeMethodRouteIdentTemplate(inst.NamePrefix, strings.TrimSuffix(caseCode, "\n"))
+}
+
+func makeCodeStringSliceLiteral(values []string, quote bool) string {
+	var elements []string
+	for _, v := range values {
+		if quote {
+			v = strconv.Quote(v)
+		}
+		elements = append(elements, v)
+	}
+	return "[]string{" + strings.Join(elements, ", ") + "}"
+}
+
+func (inst *CodeGenerateInstance) generateRouteDescriptorsCode() string {
+	var elementsCode string
+	for _, target := range inst.RouteTargets {
+		var paramNames, paramTypes []string
+		for _, param := range target.Parameters {
+			paramNames = append(paramNames, param.Name)
+			paramTypes = append(paramTypes, param.Type)
+		}
+		for _, handlerName := range target.HandlerNames() {
+			var methodCodes []string
+			for _, methodName := range target.HandlerMethods(handlerName) {
+				methodCodes = append(methodCodes, httpMethodCodeMap[methodName])
+			}
+			elementsCode += "{\n" +
+				"Ident: " + inst.makeRouteTargetIdentName(handlerName) + ",\n"
+			if "" != target.Route.AreaName {
+				elementsCode += "AreaName: " + strconv.Quote(target.Route.AreaName) + ",\n"
+			}
+			elementsCode += "Methods: " + makeCodeStringSliceLiteral(methodCodes, false) + ",\n" +
+				"HandlerName: " + strconv.Quote(handlerName) + ",\n" +
+				"Pattern: " + strconv.Quote(target.Pattern) + ",\n" +
+				"Template: " + strconv.Quote(target.Template) + ",\n"
+			if len(paramNames) > 0 {
+				elementsCode += "ParameterNames: " + makeCodeStringSliceLiteral(paramNames, true) + ",\n" +
+					"ParameterTypes: " + makeCodeStringSliceLiteral(paramTypes, true) + ",\n"
+			}
+			elementsCode += "},\n"
+		}
+	}
+	return makeCodeVarRouteDescriptors(inst.NamePrefix, strings.TrimSuffix(elementsCode, "\n"))
 }
 
 func (inst *CodeGenerateInstance) generateRouteIdentDefinitionListCode() string {
@@ -219,104 +534,67 @@ func (inst *CodeGenerateInstance) generateRouteIdentDefinitionListCode() string
 
 func (inst *CodeGenerateInstance) generateExtractFunctionOfByteSliceString(seqIndex int, seqPart *SequencePart) (extractFuncName, result string) {
 	varType := seqPart.VariableType
-	var typeTitle, typeName, typeCasting string
+	// string value is sliced from request path without copy, []byte value is a copy.
+	var typeTitle, typeName, valueExpr string
 	if varType == "string" {
 		typeTitle = "String"
 		typeName = "string"
-		typeCasting = "string"
+		valueExpr = "v[offset:idx]"
 	} else if varType == "[]byte" {
 		typeTitle = "ByteSlice"
 		typeName = "[]byte"
-		typeCasting = ""
-	}
-	var rangeBase byte = 0xFF
-	for bidx := byte(0); bidx < 128; bidx++ {
-		if seqPart.ByteMap.HasByte(bidx) && rangeBase == 0xFF {
-			rangeBase = bidx
-			break
-		}
+		valueExpr = "[]byte(v[offset:idx])"
 	}
-	bitmaskSlice := make([]uint32, 4)
-	for bidx := byte(0); bidx < 128; bidx++ {
-		if (bidx < rangeBase) || (!seqPart.ByteMap.HasByte(bidx)) {
-			continue
-		}
-		v := bidx - rangeBase
-		page := (v >> 5) & 0x3
-		nbit := v & 0x1F
-		bitmaskSlice[page] = bitmaskSlice[page] | (1 << nbit)
-	}
-	bitmaskIdent := fmt.Sprintf("Seq%03d", seqIndex)
+	rangeBase, bitmaskSlice := computeByteSliceStringBitMask(seqPart)
+	bitmaskIdent := fmt.Sprintf("Seq%03d", seqIndex) + inst.helperSuffix()
 	extractFuncName = "extract" + typeTitle + "Rx" + bitmaskIdent
-	result = makeCodeMethodExtractByteSliceStringBitMasked(typeTitle, typeName, typeCasting, rangeBase, bitmaskIdent, bitmaskSlice)
+	result = makeCodeMethodExtractByteSliceStringBitMasked(typeTitle, typeName, valueExpr, rangeBase, bitmaskIdent, bitmaskSlice)
 	return
 }
 
 func (inst *CodeGenerateInstance) generateSequenceExtractFunctions() (result string) {
 	inst.SequenceExtractFunctionName = make([]string, len(inst.symbolScope.FoundSequences))
 	hadCodeSupportConstantsExtractHexIntBuiltInR03 := false
+	helperSuffix := inst.runtimeHelperSuffix()
+	addBuiltInCode := func(codeText string) {
+		if !inst.UseSharedRuntime {
+			result += codeText
+		}
+	}
 	for seqIndex, seqPart := range inst.symbolScope.FoundSequences {
-		b0, b1 := seqPart.ByteMap.ByteMap()
 		varType := seqPart.VariableType
-		varConverter := seqPart.Converter
 		extractFuncName := ""
-		switch {
-		case (0xFFFF7FFF00000000 == b0) && (0x7FFFFFFFFFFFFFFF == b1) && (varType == "string") && (varConverter == ""):
-			extractFuncName = "extractStringBuiltInR01NoSlash"
-			result += codeMethodExtractStringBuiltInR01NoSlash
-		case (0x3FF200000000000 == b0) && (0x00000000 == b1) && (varConverter == ""):
+		switch classifySequenceExtractor(seqPart) {
+		case extractorStringBuiltInR01NoSlash:
+			extractFuncName = "extractStringBuiltInR01NoSlash" + helperSuffix
+			addBuiltInCode(makeCodeMethodExtractStringBuiltInR01NoSlash(helperSuffix))
+		case extractorIntBuiltInR01:
 			inst.NeedErrFragmentSmallerThanExpect = true
-			switch varType {
-			case "int32":
-				extractFuncName = "extractInt32BuiltInR01"
-				result += makeCodeMethodExtractIntBuiltInR01("32")
-			case "int64":
-				extractFuncName = "extractInt64BuiltInR01"
-				result += makeCodeMethodExtractIntBuiltInR01("64")
-			}
-		case (0x3FF000000000000 == b0) && (0x00000000 == b1) && (varConverter == ""):
+			typeBit := strings.TrimPrefix(varType, "int")
+			extractFuncName = "extractInt" + typeBit + "BuiltInR01" + helperSuffix
+			addBuiltInCode(makeCodeMethodExtractIntBuiltInR01(helperSuffix, typeBit))
+		case extractorUIntBuiltInR02:
 			inst.NeedErrFragmentSmallerThanExpect = true
-			switch varType {
-			case "int32":
-				extractFuncName = "extractInt32BuiltInR02"
-				result += makeCodeMethodExtractUIntBuiltInR02("Int32", "int32")
-			case "uint32":
-				extractFuncName = "extractUInt32BuiltInR02"
-				result += makeCodeMethodExtractUIntBuiltInR02("UInt32", "uint32")
-			case "int64":
-				extractFuncName = "extractInt64BuiltInR02"
-				result += makeCodeMethodExtractUIntBuiltInR02("Int64", "int64")
-			case "uint64":
-				extractFuncName = "extractUInt64BuiltInR02"
-				result += makeCodeMethodExtractUIntBuiltInR02("UInt64", "uint64")
-			}
-		case (0x3FF000000000000 == b0) && (0x7E0000007E == b1) && (varConverter == ""):
+			typeTitle := typeTitleOfIntegerType(varType)
+			extractFuncName = "extract" + typeTitle + "BuiltInR02" + helperSuffix
+			addBuiltInCode(makeCodeMethodExtractUIntBuiltInR02(helperSuffix, typeTitle, varType))
+		case extractorHexIntBuiltInR03:
 			inst.NeedErrFragmentSmallerThanExpect = true
 			if !hadCodeSupportConstantsExtractHexIntBuiltInR03 {
-				result += codeSupportConstantsExtractHexIntBuiltInR03
+				addBuiltInCode(makeCodeSupportConstantsExtractHexIntBuiltInR03(helperSuffix))
 				hadCodeSupportConstantsExtractHexIntBuiltInR03 = true
 			}
-			switch varType {
-			case "int32":
-				extractFuncName = "extractInt32BuiltInR03"
-				result += makeCodeMethodExtractHexIntBuiltInR03("Int32", "int32")
-			case "uint32":
-				extractFuncName = "extractUInt32BuiltInR03"
-				result += makeCodeMethodExtractHexIntBuiltInR03("UInt32", "uint32")
-			case "int64":
-				extractFuncName = "extractInt64BuiltInR03"
-				result += makeCodeMethodExtractHexIntBuiltInR03("Int64", "int64")
-			case "uint64":
-				extractFuncName = "extractUInt64BuiltInR03"
-				result += makeCodeMethodExtractHexIntBuiltInR03("UInt64", "uint64")
-			}
-		case ((varType == "string") || (varType == "[]byte")) && (varConverter == ""):
+			typeTitle := typeTitleOfIntegerType(varType)
+			extractFuncName = "extract" + typeTitle + "BuiltInR03" + helperSuffix
+			addBuiltInCode(makeCodeMethodExtractHexIntBuiltInR03(helperSuffix, typeTitle, varType))
+		case extractorByteSliceStringBitMasked:
 			var extractFuncCode string
 			extractFuncName, extractFuncCode = inst.generateExtractFunctionOfByteSliceString(seqIndex, seqPart)
 			result += extractFuncCode
 		}
 		if "" == extractFuncName {
-			log.Printf("WARN: empty extract function name for sequence (%d, %v)", seqIndex, seqPart)
+			reportDiagnostic(inst.diagnostics, DiagnosticWarning, DiagnosticUnknownExtractFunction, inst.routeIdentOfSequence(seqIndex),
+				"empty extract function name for sequence (%d, %v)", seqIndex, seqPart)
 			extractFuncName = "unknownExtractFunction"
 		}
 		inst.SequenceExtractFunctionName[seqIndex] = extractFuncName
@@ -326,11 +604,19 @@ func (inst *CodeGenerateInstance) generateSequenceExtractFunctions() (result str
 
 func (inst *CodeGenerateInstance) generateSubForkFanoutCode(fanoutFork *FanoutFork, terminateSerials []int32) (result string) {
 	subForks := fanoutFork.FindChildForkViaTerminateSerials(terminateSerials)
-	for _, subFork := range subForks {
+	for idx, subFork := range subForks {
 		if len(subForks) > 1 {
 			result += "// WARN: multiple sub-forks.\n"
 		}
-		result += cleanupCodeBlock(inst.generateFanoutCode(subFork), true)
+		// the next sub-fork reads path offset moved by this one, keep it inline.
+		keepInline := idx < len(subForks)-1
+		if keepInline {
+			inst.inlineDepth++
+		}
+		result += cleanupCodeBlock(inst.generateSplittableFanoutCode(fanoutFork, subFork), true)
+		if keepInline {
+			inst.inlineDepth--
+		}
 	}
 	if "" == result {
 		result = fmt.Sprintf("// WARN: empty sub-fork routing code: %v.\n", terminateSerials)
@@ -338,15 +624,120 @@ func (inst *CodeGenerateInstance) generateSubForkFanoutCode(fanoutFork *FanoutFo
 	return
 }
 
+// Default widths of branches for generating switch statement and binary search.
+const (
+	DefaultSwitchMinWidth       = 8
+	DefaultBinarySearchMinWidth = 32
+)
+
+// routeParameter is an extracted parameter visible to the forks being generated.
+type routeParameter struct {
+	name    string
+	varType string
+}
+
+// branchCode is routing logic code of a branch selected by value.
+type branchCode struct {
+	value uint64
+	code  string
+}
+
+func (inst *CodeGenerateInstance) switchMinWidth() int {
+	if inst.SwitchMinWidth > 0 {
+		return inst.SwitchMinWidth
+	}
+	return DefaultSwitchMinWidth
+}
+
+func (inst *CodeGenerateInstance) binarySearchMinWidth() int {
+	if inst.BinarySearchMinWidth > 0 {
+		return inst.BinarySearchMinWidth
+	}
+	return DefaultBinarySearchMinWidth
+}
+
+func makeCodeSwitchOfBranches(initStmt, selectExpr string, branches []branchCode) string {
+	if "" != initStmt {
+		selectExpr = initStmt + "; " + selectExpr
+	}
+	var caseCode string
+	for _, branch := range branches {
+		caseCode += makeCodeBlockSwitchCase(branch.value, branch.code)
+	}
+	return makeCodeBlockSwitch(selectExpr, caseCode)
+}
+
+func (inst *CodeGenerateInstance) makeCodeBinarySearchOfBranches(initStmt, selectExpr string, branches []branchCode) string {
+	if len(branches) <= inst.switchMinWidth() || len(branches) < 2 {
+		return makeCodeSwitchOfBranches(initStmt, selectExpr, branches)
+	}
+	pivotIndex := len(branches) / 2
+	conditionExpr := selectExpr
+	if "" != initStmt {
+		conditionExpr = initStmt + "; " + selectExpr
+	}
+	return makeCodeBlockBinarySearch(conditionExpr, branches[pivotIndex].value,
+		inst.makeCodeBinarySearchOfBranches("", selectExpr, branches[:pivotIndex]),
+		inst.makeCodeBinarySearchOfBranches("", selectExpr, branches[pivotIndex:]))
+}
+
+// makeCodeBranchSelection generate switch statement, or binary search over
+// sorted values for wide branches, which select branch by value of selectExpr.
+// The initStmt is evaluated before selection if not empty.
+func (inst *CodeGenerateInstance) makeCodeBranchSelection(initStmt, selectExpr string, branches []branchCode) string {
+	if len(branches) < inst.binarySearchMinWidth() {
+		return makeCodeSwitchOfBranches(initStmt, selectExpr, branches)
+	}
+	sortedBranches := make([]branchCode, len(branches))
+	copy(sortedBranches, branches)
+	sort.Slice(sortedBranches, func(i, j int) bool {
+		return sortedBranches[i].value < sortedBranches[j].value
+	})
+	return inst.makeCodeBinarySearchOfBranches(initStmt, selectExpr, sortedBranches)
+}
+
 func (inst *CodeGenerateInstance) generatePrefixMatching(fanoutFork *FanoutFork) (result string) {
-	routeMissingIdentName := inst.makeRouteMissingIdentName(fanoutFork.AreaName)
-	result = makeCodeBlockPrefixMatching32Start(inst.NamePrefix, routeMissingIdentName, fanoutFork.BaseOffset, fanoutFork.PrefixLiteralDigests.Depth)
-	result = strings.TrimRightFunc(result, unicode.IsSpace)
-	for _, digestSet := range fanoutFork.PrefixLiteralDigests.Digests {
-		subRoutingCode := inst.generateSubForkFanoutCode(fanoutFork, digestSet.TerminateSerials)
-		codeText := makeCodeBlockPrefixMatching32Fork(inst.NamePrefix, digestSet.Value, subRoutingCode)
-		codeText = strings.TrimRightFunc(codeText, unicode.IsSpace)
-		result += codeText
+	routeMissingIdentName := inst.makeRouteHitExpression(inst.makeRouteMissingIdentName(fanoutFork.AreaName))
+	use64 := fanoutFork.PrefixLiteralDigests.Depth > PrefixDigestLimit32
+	inst.funcScope.usePrefixMatching(use64)
+	if fallbackDigests := fanoutFork.PrefixFallbackDigests; nil != fallbackDigests {
+		inst.funcScope.usePrefixMatching(false)
+		fallbackValues := make([]string, 0, len(fallbackDigests.Digests))
+		for _, digestSet := range fallbackDigests.Digests {
+			fallbackValues = append(fallbackValues, "0x"+strconv.FormatUint(digestSet.Value, 16))
+		}
+		result = makeCodeBlockPrefixMatching64FallbackStart(inst.NamePrefix, inst.runtimeHelperSuffix(), routeMissingIdentName, fanoutFork.BaseOffset, fanoutFork.PrefixLiteralDigests.Depth, fallbackDigests.Depth, strings.Join(fallbackValues, ", "))
+	} else if use64 {
+		result = makeCodeBlockPrefixMatching64Start(inst.NamePrefix, inst.runtimeHelperSuffix(), routeMissingIdentName, fanoutFork.BaseOffset, fanoutFork.PrefixLiteralDigests.Depth)
+	} else {
+		result = makeCodeBlockPrefixMatching32Start(inst.NamePrefix, inst.runtimeHelperSuffix(), routeMissingIdentName, fanoutFork.BaseOffset, fanoutFork.PrefixLiteralDigests.Depth)
+	}
+	if digests := fanoutFork.PrefixLiteralDigests.Digests; len(digests) >= inst.switchMinWidth() {
+		selectExpr := "digest32"
+		if use64 {
+			selectExpr = "digest64"
+		}
+		branches := make([]branchCode, 0, len(digests))
+		for _, digestSet := range digests {
+			branches = append(branches, branchCode{
+				value: digestSet.Value,
+				code:  inst.generateSubForkFanoutCode(fanoutFork, digestSet.TerminateSerials),
+			})
+		}
+		result += inst.makeCodeBranchSelection("", selectExpr, branches)
+	} else {
+		result = strings.TrimRightFunc(result, unicode.IsSpace)
+		for _, digestSet := range digests {
+			subRoutingCode := inst.generateSubForkFanoutCode(fanoutFork, digestSet.TerminateSerials)
+			var codeText string
+			if use64 {
+				codeText = makeCodeBlockPrefixMatching64Fork(inst.NamePrefix, digestSet.Value, subRoutingCode)
+			} else {
+				codeText = makeCodeBlockPrefixMatching32Fork(inst.NamePrefix, uint32(digestSet.Value), subRoutingCode)
+			}
+			codeText = strings.TrimRightFunc(codeText, unicode.IsSpace)
+			result += codeText
+		}
 	}
 	result += "\n"
 	if routeMissingIdentName != "" && fanoutFork.IsTipAreaFork() {
@@ -356,15 +747,28 @@ func (inst *CodeGenerateInstance) generatePrefixMatching(fanoutFork *FanoutFork)
 }
 
 func (inst *CodeGenerateInstance) generateFuzzyMatchingBoundCheck(fanoutFork *FanoutFork, bestDepth int) (result string) {
-	routeMissingIdentName := inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+	routeMissingIdentName := inst.makeRouteHitExpression(inst.makeRouteMissingIdentName(fanoutFork.AreaName))
 	if offsetSum := fanoutFork.BaseOffset + bestDepth; 0 != offsetSum {
 		return makeCodeBlockFuzzyMatchingBoundCheckNonZero(inst.NamePrefix, routeMissingIdentName, fanoutFork.BaseOffset, bestDepth)
 	}
 	return makeCodeBlockFuzzyMatchingBoundCheckZero(inst.NamePrefix, routeMissingIdentName)
 }
 
+func (inst *CodeGenerateInstance) makeFuzzyMatchingBranches(fanoutFork *FanoutFork, trackSets []*FanoutFuzzyTrackSet) (branches []branchCode) {
+	for _, trackSet := range trackSets {
+		branches = append(branches, branchCode{
+			value: uint64(trackSet.Value),
+			code:  inst.generateSubForkFanoutCode(fanoutFork, trackSet.TerminateSerials),
+		})
+	}
+	return
+}
+
 func (inst *CodeGenerateInstance) generateFuzzyMatchingU8(fanoutFork *FanoutFork) (result string) {
 	result = inst.generateFuzzyMatchingBoundCheck(fanoutFork, fanoutFork.FuzzyTracker.BestU8Depth)
+	if trackSets := fanoutFork.FuzzyTracker.BestU8; len(trackSets) >= inst.switchMinWidth() {
+		return result + inst.makeCodeBranchSelection("ch := reqPath[reqPathOffset]", "ch", inst.makeFuzzyMatchingBranches(fanoutFork, trackSets))
+	}
 	for idx, trackSet := range fanoutFork.FuzzyTracker.BestU8 {
 		subRoutingCode := inst.generateSubForkFanoutCode(fanoutFork, trackSet.TerminateSerials)
 		var codeBlock string
@@ -380,6 +784,9 @@ func (inst *CodeGenerateInstance) generateFuzzyMatchingU8(fanoutFork *FanoutFork
 
 func (inst *CodeGenerateInstance) generateFuzzyMatchingU16(fanoutFork *FanoutFork) (result string) {
 	result = inst.generateFuzzyMatchingBoundCheck(fanoutFork, fanoutFork.FuzzyTracker.BestU16Depth)
+	if trackSets := fanoutFork.FuzzyTracker.BestU16; len(trackSets) >= inst.switchMinWidth() {
+		return result + inst.makeCodeBranchSelection("ch := (uint16(reqPath[reqPathOffset-1]) << 8) | uint16(reqPath[reqPathOffset])", "ch", inst.makeFuzzyMatchingBranches(fanoutFork, trackSets))
+	}
 	for idx, trackSet := range fanoutFork.FuzzyTracker.BestU16 {
 		subRoutingCode := inst.generateSubForkFanoutCode(fanoutFork, trackSet.TerminateSerials)
 		var codeBlock string
@@ -404,7 +811,7 @@ func (inst *CodeGenerateInstance) generateFuzzyMatching(fanoutFork *FanoutFork)
 	}
 	result += "\n"
 	if fanoutFork.IsTipAreaFork() {
-		routeMissingIdentName := inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+		routeMissingIdentName := inst.makeRouteHitExpression(inst.makeRouteMissingIdentName(fanoutFork.AreaName))
 		result += "return " + routeMissingIdentName + ", nil\n"
 	}
 	return
@@ -414,8 +821,13 @@ func (inst *CodeGenerateInstance) generateGetParameter(fanoutFork *FanoutFork) (
 	seqIndex := fanoutFork.SequenceIndex
 	seqPart := inst.symbolScope.FoundSequences[seqIndex]
 	extractFuncName := inst.SequenceExtractFunctionName[seqIndex]
-	routeMissingIdentName := inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+	routeMissingIdentName := inst.makeRouteHitExpression(inst.makeRouteMissingIdentName(fanoutFork.AreaName))
+	inst.paramScope = append(inst.paramScope, routeParameter{
+		name:    fanoutFork.SequenceVarName,
+		varType: seqPart.VariableType,
+	})
 	subRoutingCode := inst.generateSubForkFanoutCode(fanoutFork, fanoutFork.CoveredTerminals)
+	inst.paramScope = inst.paramScope[:len(inst.paramScope)-1]
 	result = makeCodeBlockGetParameter(inst.NamePrefix, routeMissingIdentName, fanoutFork.SequenceVarName, seqPart.VariableType, extractFuncName, fanoutFork.BaseOffset, subRoutingCode)
 	return
 }
@@ -428,15 +840,15 @@ func (inst *CodeGenerateInstance) generateInvokeHandler(fanoutFork *FanoutFork)
 			result += "fallthrough\n"
 		} else {
 			handlerName := invokeProfile.HandlerName
-			result += fmt.Sprintf("%s.%s(w, req, reqPathOffset%s",
+			handlerCallCode := fmt.Sprintf("%s.%s(w, req, reqPathOffset%s",
 				inst.ReceiverName,
 				handlerName,
 				codeTemplateGenIntPlus(fanoutFork.BaseOffset))
 			for _, paramName := range fanoutFork.AvailableSequenceVarName {
-				result = result + ", " + paramName
+				handlerCallCode = handlerCallCode + ", " + paramName
 			}
-			result += ")\n"
-			result += "return " + inst.makeRouteTargetIdentName(handlerName) + ", nil\n"
+			handlerCallCode += ")"
+			result += inst.makeInvokeHandlerCode(fanoutFork, handlerName, handlerCallCode)
 		}
 	}
 	result += "}\n"
@@ -460,79 +872,295 @@ func (inst *CodeGenerateInstance) generateFanoutCode(fanoutFork *FanoutFork) (re
 
 func (inst *CodeGenerateInstance) writeRouteIdentConstants() (err error) {
 	codeText := makeCodeTypeRouteIdent(inst.NamePrefix)
-	if _, err = inst.fp.WriteString(codeText); nil != err {
+	if _, err = inst.codeBuf.WriteString(codeText); nil != err {
 		return
 	}
-	codeText = inst.generateRouteIdentDefinitionListCode()
-	_, err = inst.fp.WriteString(codeText)
+	codeText = inst.generateRouteIdentDefinitionListCode() +
+		inst.generateRouteIdentStringMethodCode() +
+		inst.generateRouteIdentTemplateMethodCode() +
+		inst.generateRouteDescriptorsCode()
+	_, err = inst.codeBuf.WriteString(codeText)
 	return
 }
 
-func (inst *CodeGenerateInstance) writeModuleImports() (err error) {
-	if _, err = inst.fp.WriteString("import (\n"); nil != err {
-		return
+func makeCodeModuleImports(importModules []string) (result string) {
+	result = "import (\n"
+	for _, impStmt := range importModules {
+		result += impStmt + "\n"
 	}
-	for _, impStmt := range inst.ImportModules {
-		if _, err = inst.fp.WriteString(impStmt + "\n"); nil != err {
-			return
-		}
-	}
-	_, err = inst.fp.WriteString(")\n\n")
-	return
+	return result + ")\n\n"
 }
 
 func (inst *CodeGenerateInstance) writeErrorVariables() (err error) {
 	switch {
-	case inst.NeedErrFragmentSmallerThanExpect:
-		if _, err = inst.fp.WriteString(codeErrFragmentSmallerThanExpect); nil != err {
+	case inst.NeedErrFragmentSmallerThanExpect && !inst.UseSharedRuntime:
+		if _, err = inst.codeBuf.WriteString(makeCodeErrFragmentSmallerThanExpect(inst.runtimeHelperSuffix())); nil != err {
 			return
 		}
 	}
 	return nil
 }
 
-func (inst *CodeGenerateInstance) writePrefixMatchingDigest32Runtime() (routingVarCode string, err error) {
-	if !inst.UsePrefixMatching {
+func (inst *CodeGenerateInstance) writePrefixMatchingDigestRuntime() (err error) {
+	if inst.UseSharedRuntime {
 		return
 	}
-	routingVarCode = "var digest32 uint32\n"
-	_, err = inst.fp.WriteString(codeFunctionComputePrefixMatching32)
+	if inst.UsePrefixMatching {
+		if _, err = inst.codeBuf.WriteString(makeCodeFunctionComputePrefixMatching32(inst.runtimeHelperSuffix())); nil != err {
+			return
+		}
+	}
+	if inst.UsePrefixMatching64 {
+		_, err = inst.codeBuf.WriteString(makeCodeFunctionComputePrefixMatching64(inst.runtimeHelperSuffix()))
+	}
 	return
 }
 
-// Generate code with given configuration.
-func (inst *CodeGenerateInstance) Generate() (err error) {
+// handlerInterfaceName return name of interface of handler methods for UseHandlerInterface.
+func (inst *CodeGenerateInstance) handlerInterfaceName() string {
+	return inst.RouteMethodName + "Handler" + inst.helperSuffix()
+}
+
+// handlerDispatchTypeName return name of type carrying routing logic for UseHandlerInterface.
+func (inst *CodeGenerateInstance) handlerDispatchTypeName() string {
+	return inst.RouteMethodName + "Dispatch" + inst.helperSuffix()
+}
+
+// routeLogicTypeName return name of type which route method with routing logic is declared on.
+func (inst *CodeGenerateInstance) routeLogicTypeName() string {
+	if inst.UseHandlerInterface {
+		return inst.handlerDispatchTypeName()
+	}
+	return inst.HandlerTypeName
+}
+
+// handlerMethodParameterDecls return parameter declarations following path offset of each handler method.
+func (inst *CodeGenerateInstance) handlerMethodParameterDecls() (handlerNames []string, paramDecls map[string]string) {
+	paramDecls = make(map[string]string)
+	for _, target := range inst.RouteTargets {
+		var decls string
+		for _, param := range target.Parameters {
+			decls += ", " + param.Name + " " + param.Type
+		}
+		for _, handlerName := range target.HandlerNames() {
+			if _, ok := paramDecls[handlerName]; ok {
+				continue
+			}
+			handlerNames = append(handlerNames, handlerName)
+			paramDecls[handlerName] = decls
+		}
+	}
+	return
+}
+
+func (inst *CodeGenerateInstance) generateHandlerInterfaceCode() string {
+	handlerNames, paramDecls := inst.handlerMethodParameterDecls()
+	var methodDecls string
+	for _, handlerName := range handlerNames {
+		methodDecls += handlerName + "(w http.ResponseWriter, req *http.Request, pathOffset int" + paramDecls[handlerName] + ")\n"
+	}
+	if "" != inst.ObserverMethodName {
+		methodDecls += inst.ObserverMethodName + "(req *http.Request, ident " + inst.NamePrefix + "RouteIdent, template string)\n"
+	}
+	return makeCodeRouteHandlerInterface(inst.NamePrefix, inst.ReceiverName, inst.HandlerTypeName, inst.RouteMethodName,
+		inst.handlerInterfaceName(), inst.handlerDispatchTypeName(), strings.TrimSuffix(methodDecls, "\n"))
+}
+
+// generateRouteMethodCode generate route method for given handler type.
+// Receiver name of handler type must be the same as ReceiverName.
+// Route program backend does not use it, see writeRouteProgram().
+func (inst *CodeGenerateInstance) generateRouteMethodCode(handlerTypeName, routeMethodName string) string {
+	inst.funcScope = &routeFunctionScope{}
+	inst.routeFuncs = newRouteFunctionSet(handlerTypeName, routeMethodName)
+	routingLogicCode := cleanupCodeBlock(inst.generateFanoutCode(inst.rootFanoutFork), true)
+	routingLogicCode = inst.funcScope.makeVariableDeclarations() + routingLogicCode
+	return makeCodeMethodRouteEnterance(inst.NamePrefix, inst.ReceiverName, handlerTypeName, routeMethodName, routingLogicCode) +
+		inst.routeFuncs.codeText
+}
+
+// generateBodyCode generate code following import statements.
+// Modules required by generated code are collected into ImportModules.
+func (inst *CodeGenerateInstance) generateBodyCode() (bodyCode string, err error) {
 	if err = inst.validateConfiguration(); nil != err {
 		return
 	}
-	if _, err = inst.fp.WriteString(generatedCodeIndicatorLine +
-		"package " + inst.PackageName + "\n\n"); nil != err {
-		return
+	inst.codeBuf = &bytes.Buffer{}
+	var seqExtractCode string
+	if inst.UseRouteProgram {
+		inst.NeedErrFragmentSmallerThanExpect = true
+	} else {
+		seqExtractCode = inst.generateSequenceExtractFunctions()
 	}
-	seqExtractCode := inst.generateSequenceExtractFunctions()
 	inst.collectImportForErrors()
-	if err = inst.writeModuleImports(); nil != err {
+	inst.collectImportForRouteHits()
+	if err = inst.writeRouteIdentConstants(); nil != err {
 		return
 	}
-	if err = inst.writeRouteIdentConstants(); nil != err {
+	if err = inst.writeRouteHitCounters(); nil != err {
 		return
 	}
 	if err = inst.writeErrorVariables(); nil != err {
 		return
 	}
-	if _, err = inst.fp.WriteString(seqExtractCode); nil != err {
+	if inst.UseRouteProgram {
+		if err = inst.writeRouteProgram(); nil != err {
+			return
+		}
+		return inst.codeBuf.String(), nil
+	}
+	if _, err = inst.codeBuf.WriteString(seqExtractCode); nil != err {
 		return
 	}
-	var routingLogicCode string
-	var varDefineCode string
-	if varDefineCode, err = inst.writePrefixMatchingDigest32Runtime(); nil != err {
+	if err = inst.writePrefixMatchingDigestRuntime(); nil != err {
 		return
 	}
-	routingLogicCode += varDefineCode
-	routingLogicCode += cleanupCodeBlock(inst.generateFanoutCode(inst.rootFanoutFork), true)
-	methodCode := makeCodeMethodRouteEnterance(inst.NamePrefix, inst.ReceiverName, inst.HandlerTypeName, inst.RouteMethodName, routingLogicCode)
-	if _, err = inst.fp.WriteString(methodCode); nil != err {
+	var methodCode string
+	if inst.UseHandlerInterface {
+		methodCode = inst.generateHandlerInterfaceCode()
+	}
+	methodCode += inst.generateRouteMethodCode(inst.routeLogicTypeName(), inst.RouteMethodName)
+	if _, err = inst.codeBuf.WriteString(methodCode); nil != err {
+		return
+	}
+	return inst.codeBuf.String(), nil
+}
+
+func (inst *CodeGenerateInstance) generateCode() (err error) {
+	bodyCode, err := inst.generateBodyCode()
+	if nil != err {
+		return
+	}
+	inst.codeBuf = &bytes.Buffer{}
+	_, err = inst.codeBuf.WriteString(generatedCodeIndicatorLine +
+		"package " + inst.PackageName + "\n\n" +
+		makeCodeModuleImports(inst.ImportModules) +
+		bodyCode)
+	return
+}
+
+// GenerateTo write formatted code with given configuration into given writer.
+// Nothing is written if code generation or formatting failed.
+func (inst *CodeGenerateInstance) GenerateTo(w io.Writer) (err error) {
+	if err = inst.generateCode(); nil != err {
+		return
+	}
+	codeText, err := formatCode(inst.codeBuf.Bytes())
+	if nil != err {
+		return
+	}
+	_, err = w.Write(codeText)
+	return
+}
+
+// Generate code with given configuration into code file given to OpenCodeGenerateInstance().
+func (inst *CodeGenerateInstance) Generate() (err error) {
+	if "" == inst.codeFilePath {
+		return ErrCodeFilePathRequired
+	}
+	return inst.GenerateFile(inst.codeFilePath)
+}
+
+// GenerateFile write formatted code with given configuration into given file.
+// The file is replaced atomically and left untouched on error.
+func (inst *CodeGenerateInstance) GenerateFile(codeFilePath string) (err error) {
+	var buf bytes.Buffer
+	if err = inst.GenerateTo(&buf); nil != err {
 		return
 	}
+	return writeFileAtomically(codeFilePath, buf.Bytes())
+}
+
+// VerifyRouterInstances check given code generation instances can be generated
+// into the same package without name collision.
+// Only routers generated into the same package should be given.
+func VerifyRouterInstances(insts []*CodeGenerateInstance) error {
+	for idx, inst := range insts {
+		for _, prevInst := range insts[:idx] {
+			if (prevInst.UseSharedRuntime != inst.UseSharedRuntime) && ("" == prevInst.runtimeHelperSuffix()+inst.runtimeHelperSuffix()) {
+				return &ErrRouterConflict{
+					Option:  "runtime",
+					Value1:  prevInst.NamePrefix,
+					Value2:  inst.NamePrefix,
+					Message: "router without shared runtime requires name prefix when other router uses shared runtime",
+				}
+			}
+			if prevInst.helperSuffix() == inst.helperSuffix() {
+				return &ErrRouterConflict{
+					Option:  "prefix",
+					Value1:  prevInst.NamePrefix,
+					Value2:  inst.NamePrefix,
+					Message: "routers in the same package require distinct name prefix",
+				}
+			}
+			if (prevInst.HandlerTypeName == inst.HandlerTypeName) && (prevInst.RouteMethodName == inst.RouteMethodName) {
+				return &ErrRouterConflict{
+					Option:  "methodName",
+					Value1:  prevInst.HandlerTypeName + "." + prevInst.RouteMethodName,
+					Value2:  inst.HandlerTypeName + "." + inst.RouteMethodName,
+					Message: "route method is declared more than once on handler type",
+				}
+			}
+		}
+	}
 	return nil
 }
+
+// GenerateRoutersTo write formatted code of several routers into given writer as one file.
+// All instances must have the same PackageName and pass VerifyRouterInstances().
+// Nothing is written if code generation or formatting failed.
+func GenerateRoutersTo(w io.Writer, insts ...*CodeGenerateInstance) (err error) {
+	if 0 == len(insts) {
+		return errors.New("at least one router is required")
+	}
+	if err = VerifyRouterInstances(insts); nil != err {
+		return
+	}
+	var importModules []string
+	var bodyCode string
+	for _, inst := range insts {
+		if inst.PackageName != insts[0].PackageName {
+			return &ErrRouterConflict{
+				Option:  "package",
+				Value1:  insts[0].PackageName,
+				Value2:  inst.PackageName,
+				Message: "routers in the same file require the same package name",
+			}
+		}
+		instBodyCode, err := inst.generateBodyCode()
+		if nil != err {
+			return err
+		}
+		bodyCode += instBodyCode
+		for _, modName := range inst.ImportModules {
+			found := false
+			for _, n := range importModules {
+				if n == modName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				importModules = append(importModules, modName)
+			}
+		}
+	}
+	codeText, err := formatCode([]byte(generatedCodeIndicatorLine +
+		"package " + insts[0].PackageName + "\n\n" +
+		makeCodeModuleImports(importModules) +
+		bodyCode))
+	if nil != err {
+		return
+	}
+	_, err = w.Write(codeText)
+	return
+}
+
+// GenerateRoutersFile write formatted code of several routers into given file.
+// The file is replaced atomically and left untouched on error.
+func GenerateRoutersFile(codeFilePath string, insts ...*CodeGenerateInstance) (err error) {
+	var buf bytes.Buffer
+	if err = GenerateRoutersTo(&buf, insts...); nil != err {
+		return
+	}
+	return writeFileAtomically(codeFilePath, buf.Bytes())
+}
@@ -0,0 +1,49 @@
+package httproutegen
+
+import (
+	"testing"
+)
+
+type collectDiagnostics struct {
+	diags []*Diagnostic
+}
+
+func (d *collectDiagnostics) Report(diag *Diagnostic) {
+	d.diags = append(d.diags, diag)
+}
+
+func (d *collectDiagnostics) count(code DiagnosticCode) (result int) {
+	for _, diag := range d.diags {
+		if diag.Code == code {
+			result++
+		}
+	}
+	return
+}
+
+func TestCheckSharedHandlers(t *testing.T) {
+	testCases := []struct {
+		configText string
+		warnCount  int
+	}{
+		{"route:\n- c: 'a/{0-9, n int32}'\n  handler:\n    get: \"show\"\n- c: 'b/{0-9, n int32}'\n  handler:\n    get: \"show\"\n", 1},
+		{"route:\n- c: 'a/{0-9, n int32}'\n  handler:\n    get: \"show\"\n    post: \"=get\"\n- c: 'b/{0-9, n int32}'\n  handler:\n    get: \"list\"\n", 0},
+	}
+	for idx, tc := range testCases {
+		rootRouteEntry := loadTestRouteConfig(t, tc.configText)
+		fanoutInstance, err := MakeFanoutInstance(rootRouteEntry)
+		if nil != err {
+			t.Fatalf("case %d: cannot create fanout instance: %v", idx, err)
+		}
+		if err = fanoutInstance.ExpandFanout(); nil != err {
+			t.Fatalf("case %d: cannot expand fanout instance: %v", idx, err)
+		}
+		var diagnostics collectDiagnostics
+		if _, err = NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, &diagnostics); nil != err {
+			t.Fatalf("case %d: cannot create code generation instance: %v", idx, err)
+		}
+		if count := diagnostics.count(DiagnosticHandlerSharedByRoutes); count != tc.warnCount {
+			t.Errorf("case %d: expect %d warning(s) of shared handler but have %d: %v", idx, tc.warnCount, count, diagnostics.diags)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package httproutegen
+
+import (
+	"strconv"
+)
+
+// countRouteHits check if generated code counts hits of route misses and route targets.
+func (inst *CodeGenerateInstance) countRouteHits() bool {
+	return inst.CountRouteHits || inst.TimeRouteHandlers
+}
+
+// makeRouteHitExpression wrap given route miss or route target ident with hit counting.
+// Empty ident is kept empty.
+func (inst *CodeGenerateInstance) makeRouteHitExpression(identName string) string {
+	if ("" == identName) || !inst.countRouteHits() {
+		return identName
+	}
+	return "countRouteHit" + inst.helperSuffix() + "(" + identName + ")"
+}
+
+// makeInvokeHandlerCode generate handler invocation and return of route target ident.
+// The observer is invoked with template of route target of given fork before
+// the handler when ObserverMethodName is set.
+// Time spent in handler is measured when TimeRouteHandlers is set.
+func (inst *CodeGenerateInstance) makeInvokeHandlerCode(fanoutFork *FanoutFork, handlerName, handlerCallCode string) string {
+	identName := inst.makeRouteTargetIdentName(handlerName)
+	var observeCode string
+	if "" != inst.ObserverMethodName {
+		template, _ := inst.routeTemplateOfFork(fanoutFork)
+		observeCode = inst.ReceiverName + "." + inst.ObserverMethodName + "(req, " + identName + ", " + strconv.Quote(template) + ")\n"
+	}
+	if !inst.TimeRouteHandlers {
+		return observeCode + handlerCallCode + "\n" +
+			"return " + inst.makeRouteHitExpression(identName) + ", nil\n"
+	}
+	return observeCode + "handlerStartAt := time.Now()\n" +
+		handlerCallCode + "\n" +
+		"return timeRouteHandler" + inst.helperSuffix() + "(" + identName + ", handlerStartAt), nil\n"
+}
+
+func (inst *CodeGenerateInstance) collectImportForRouteHits() {
+	if inst.countRouteHits() {
+		inst.addImportModule("sync/atomic", false)
+	}
+	if inst.TimeRouteHandlers {
+		inst.addImportModule("time", false)
+	}
+}
+
+func (inst *CodeGenerateInstance) writeRouteHitCounters() (err error) {
+	if !inst.countRouteHits() {
+		return
+	}
+	identCount := len(inst.routeIdentNames())
+	codeText := makeCodeRouteHitCounters(inst.NamePrefix, inst.helperSuffix(), identCount)
+	if inst.TimeRouteHandlers {
+		codeText += makeCodeRouteHandlerTimers(inst.NamePrefix, inst.helperSuffix(), identCount)
+	}
+	_, err = inst.codeBuf.WriteString(codeText)
+	return
+}
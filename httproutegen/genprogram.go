@@ -0,0 +1,390 @@
+package httproutegen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Operations of route program.
+// Route program compares bytes of request path as they are, so the values
+// of matching are digests of path bytes instead of Symbol.ByteCode() which
+// encodes symbols of route component.
+const (
+	routeProgramOpPrefixMatching byte = iota + 1
+	routeProgramOpFuzzyMatchingU8
+	routeProgramOpFuzzyMatchingU16
+	routeProgramOpGetParameter
+	routeProgramOpInvokeHandler
+	routeProgramOpPrefixMatchingFallback
+)
+
+// Extractors of parameters in route program.
+const (
+	routeProgramExtractNoSlash byte = iota + 1
+	routeProgramExtractSignedDecimal
+	routeProgramExtractUnsignedDecimal
+	routeProgramExtractHex
+	routeProgramExtractBitMasked
+)
+
+// routeProgramMethodNames is the request methods indexed by route program,
+// it must be in the same order as routeProgramMethods in generated code.
+var routeProgramMethodNames = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// routeProgramCompiler serialize fanout forks into route program.
+type routeProgramCompiler struct {
+	inst        *CodeGenerateInstance
+	identValues map[string]uint64
+	params      []routeParameter
+	slotCount   int
+
+	invokeHandlerCodes []string
+	callSiteOfCode     map[string]int
+}
+
+func newRouteProgramCompiler(inst *CodeGenerateInstance) *routeProgramCompiler {
+	identValues := make(map[string]uint64)
+	for idx, identName := range inst.routeIdentNames() {
+		identValues[identName] = uint64(idx)
+	}
+	return &routeProgramCompiler{
+		inst:           inst,
+		identValues:    identValues,
+		callSiteOfCode: make(map[string]int),
+	}
+}
+
+func appendRouteProgramUvarint(program []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(program, buf[:n]...)
+}
+
+func (c *routeProgramCompiler) identValue(identName string) uint64 {
+	if "" == identName {
+		return 0
+	}
+	return c.identValues[identName]
+}
+
+func (c *routeProgramCompiler) compileSubForks(fanoutFork *FanoutFork, terminateSerials []int32) (program []byte, err error) {
+	for _, subFork := range fanoutFork.FindChildForkViaTerminateSerials(terminateSerials) {
+		var subProgram []byte
+		if subProgram, err = c.compileFork(subFork); nil != err {
+			return
+		}
+		program = append(program, subProgram...)
+	}
+	return
+}
+
+// routeProgramBranch is a branch of matching selected by value.
+type routeProgramBranch struct {
+	value            uint64
+	terminateSerials []int32
+}
+
+// compileMatching serialize prefix or fuzzy matching with its branches.
+// Each branch is the matching value, length of body and the body.
+// The fallback is placed before length of body if given.
+func (c *routeProgramCompiler) compileMatching(op byte, fanoutFork *FanoutFork, depth int, failIdent, fallIdent string, fallback []byte, branches []routeProgramBranch) (program []byte, err error) {
+	var body []byte
+	for _, branch := range branches {
+		var subProgram []byte
+		if subProgram, err = c.compileSubForks(fanoutFork, branch.terminateSerials); nil != err {
+			return
+		}
+		body = appendRouteProgramUvarint(body, branch.value)
+		body = appendRouteProgramUvarint(body, uint64(len(subProgram)))
+		body = append(body, subProgram...)
+	}
+	program = append(program, op)
+	program = appendRouteProgramUvarint(program, uint64(fanoutFork.BaseOffset))
+	program = appendRouteProgramUvarint(program, uint64(depth))
+	program = appendRouteProgramUvarint(program, c.identValue(failIdent))
+	program = appendRouteProgramUvarint(program, c.identValue(fallIdent))
+	program = append(program, fallback...)
+	program = appendRouteProgramUvarint(program, uint64(len(body)))
+	return append(program, body...), nil
+}
+
+func (c *routeProgramCompiler) compilePrefixMatching(fanoutFork *FanoutFork) ([]byte, error) {
+	namePrefix := c.inst.NamePrefix
+	routeMissingIdentName := c.inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+	var fallIdent string
+	if routeMissingIdentName != "" && fanoutFork.IsTipAreaFork() {
+		fallIdent = routeMissingIdentName
+	}
+	var branches []routeProgramBranch
+	for _, digestSet := range fanoutFork.PrefixLiteralDigests.Digests {
+		branches = append(branches, routeProgramBranch{
+			value:            digestSet.Value,
+			terminateSerials: digestSet.TerminateSerials,
+		})
+	}
+	op := routeProgramOpPrefixMatching
+	var fallback []byte
+	if fallbackDigests := fanoutFork.PrefixFallbackDigests; nil != fallbackDigests {
+		// Fallback is the depth, length of values and values of the first step of merged digest.
+		var values []byte
+		for _, digestSet := range fallbackDigests.Digests {
+			values = appendRouteProgramUvarint(values, digestSet.Value)
+		}
+		op = routeProgramOpPrefixMatchingFallback
+		fallback = appendRouteProgramUvarint(fallback, uint64(fallbackDigests.Depth))
+		fallback = appendRouteProgramUvarint(fallback, uint64(len(values)))
+		fallback = append(fallback, values...)
+	}
+	return c.compileMatching(op, fanoutFork, fanoutFork.PrefixLiteralDigests.Depth,
+		pickNonEmptyIdent(routeMissingIdentName, namePrefix+"RouteError"), fallIdent, fallback, branches)
+}
+
+func (c *routeProgramCompiler) compileFuzzyMatching(fanoutFork *FanoutFork) ([]byte, error) {
+	var op byte
+	var bestDepth int
+	var trackSets []*FanoutFuzzyTrackSet
+	switch fanoutFork.FuzzyModeBit {
+	case 8:
+		op, bestDepth, trackSets = routeProgramOpFuzzyMatchingU8, fanoutFork.FuzzyTracker.BestU8Depth, fanoutFork.FuzzyTracker.BestU8
+	case 16:
+		op, bestDepth, trackSets = routeProgramOpFuzzyMatchingU16, fanoutFork.FuzzyTracker.BestU16Depth, fanoutFork.FuzzyTracker.BestU16
+	default:
+		return nil, fmt.Errorf("unknown fuzzy mode bit: %d", fanoutFork.FuzzyModeBit)
+	}
+	routeMissingIdentName := c.inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+	var fallIdent string
+	if fanoutFork.IsTipAreaFork() {
+		fallIdent = routeMissingIdentName
+	}
+	var branches []routeProgramBranch
+	for _, trackSet := range trackSets {
+		branches = append(branches, routeProgramBranch{
+			value:            uint64(trackSet.Value),
+			terminateSerials: trackSet.TerminateSerials,
+		})
+	}
+	return c.compileMatching(op, fanoutFork, bestDepth,
+		pickNonEmptyIdent(routeMissingIdentName, c.inst.NamePrefix+"RouteIncomplete"), fallIdent, nil, branches)
+}
+
+// appendRouteProgramExtractor append extractor of given sequence.
+// Bit-masked extractor is followed by range base and 16 bytes of mask.
+func appendRouteProgramExtractor(program []byte, seqPart *SequencePart) ([]byte, error) {
+	switch classifySequenceExtractor(seqPart) {
+	case extractorStringBuiltInR01NoSlash:
+		return append(program, routeProgramExtractNoSlash), nil
+	case extractorIntBuiltInR01:
+		return append(program, routeProgramExtractSignedDecimal), nil
+	case extractorUIntBuiltInR02:
+		return append(program, routeProgramExtractUnsignedDecimal), nil
+	case extractorHexIntBuiltInR03:
+		return append(program, routeProgramExtractHex), nil
+	case extractorByteSliceStringBitMasked:
+		rangeBase, bitmaskSlice := computeByteSliceStringBitMask(seqPart)
+		program = append(program, routeProgramExtractBitMasked, rangeBase)
+		for _, mask := range bitmaskSlice {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], mask)
+			program = append(program, buf[:]...)
+		}
+		return program, nil
+	}
+	return nil, fmt.Errorf("no extractor of route program for sequence: type=%v, converter=%v", seqPart.VariableType, seqPart.Converter)
+}
+
+func (c *routeProgramCompiler) compileGetParameter(fanoutFork *FanoutFork) (program []byte, err error) {
+	seqPart := c.inst.symbolScope.FoundSequences[fanoutFork.SequenceIndex]
+	routeMissingIdentName := c.inst.makeRouteMissingIdentName(fanoutFork.AreaName)
+	program = append(program, routeProgramOpGetParameter)
+	program = appendRouteProgramUvarint(program, uint64(fanoutFork.BaseOffset))
+	if program, err = appendRouteProgramExtractor(program, seqPart); nil != err {
+		return
+	}
+	slotIndex := len(c.params)
+	c.params = append(c.params, routeParameter{
+		name:    fanoutFork.SequenceVarName,
+		varType: seqPart.VariableType,
+	})
+	if len(c.params) > c.slotCount {
+		c.slotCount = len(c.params)
+	}
+	body, err := c.compileSubForks(fanoutFork, fanoutFork.CoveredTerminals)
+	c.params = c.params[:slotIndex]
+	if nil != err {
+		return
+	}
+	program = appendRouteProgramUvarint(program, uint64(slotIndex))
+	program = appendRouteProgramUvarint(program, c.identValue(pickNonEmptyIdent(routeMissingIdentName, c.inst.NamePrefix+"RouteError")))
+	program = appendRouteProgramUvarint(program, uint64(len(body)))
+	return append(program, body...), nil
+}
+
+// makeRouteProgramParameterExpression return expression of parameter value in slot for handler invocation.
+func makeRouteProgramParameterExpression(slotIndex int, varType string) string {
+	slotExpr := "slots[" + strconv.FormatInt(int64(slotIndex), 10) + "]"
+	switch varType {
+	case "string":
+		return "reqPath[" + slotExpr + ".start:" + slotExpr + ".end]"
+	case "[]byte":
+		return "[]byte(reqPath[" + slotExpr + ".start:" + slotExpr + ".end])"
+	case "uint64":
+		return slotExpr + ".value"
+	}
+	return varType + "(" + slotExpr + ".value)"
+}
+
+// addCallSite add handler invocation into handler table and return its call site number.
+// Call sites with the same invocation code share the entry of handler table.
+func (c *routeProgramCompiler) addCallSite(fanoutFork *FanoutFork, handlerName string) (callSite int, err error) {
+	var paramExprs []string
+	for _, paramName := range fanoutFork.AvailableSequenceVarName {
+		slotIndex := len(c.params) - 1
+		for ; slotIndex >= 0; slotIndex-- {
+			if c.params[slotIndex].name == paramName {
+				break
+			}
+		}
+		if slotIndex < 0 {
+			return 0, fmt.Errorf("parameter %s of handler %s is not extracted", paramName, handlerName)
+		}
+		paramExprs = append(paramExprs, ", "+makeRouteProgramParameterExpression(slotIndex, c.params[slotIndex].varType))
+	}
+	invokeCode := c.inst.makeInvokeHandlerCode(fanoutFork, handlerName, c.inst.ReceiverName+"."+handlerName+"(w, req, pathOffset"+strings.Join(paramExprs, "")+")")
+	if callSite = c.callSiteOfCode[invokeCode]; 0 != callSite {
+		return callSite, nil
+	}
+	c.invokeHandlerCodes = append(c.invokeHandlerCodes, invokeCode)
+	callSite = len(c.invokeHandlerCodes)
+	c.callSiteOfCode[invokeCode] = callSite
+	return callSite, nil
+}
+
+// compileInvokeHandler serialize pairs of request method and call site.
+// Methods falling through to the next handler profile take call site of that profile.
+func (c *routeProgramCompiler) compileInvokeHandler(fanoutFork *FanoutFork) (program []byte, err error) {
+	invokeProfiles := fanoutFork.InvokeHandlerFanout.Route.HandlerProfile.InvokeProfiles
+	callSites := make([]int, len(invokeProfiles))
+	for idx, invokeProfile := range invokeProfiles {
+		if invokeProfile.SameNext {
+			continue
+		}
+		if callSites[idx], err = c.addCallSite(fanoutFork, invokeProfile.HandlerName); nil != err {
+			return
+		}
+	}
+	for idx := len(invokeProfiles) - 2; idx >= 0; idx-- {
+		if invokeProfiles[idx].SameNext {
+			callSites[idx] = callSites[idx+1]
+		}
+	}
+	var body []byte
+	count := 0
+	for idx, invokeProfile := range invokeProfiles {
+		methodIndex := -1
+		for mIdx, methodName := range routeProgramMethodNames {
+			if methodName == invokeProfile.RequestMethod {
+				methodIndex = mIdx
+				break
+			}
+		}
+		if methodIndex < 0 {
+			return nil, fmt.Errorf("request method %s is not supported by route program", invokeProfile.RequestMethod)
+		}
+		if 0 == callSites[idx] {
+			continue
+		}
+		body = append(body, byte(methodIndex))
+		body = appendRouteProgramUvarint(body, uint64(callSites[idx]))
+		count++
+	}
+	program = append(program, routeProgramOpInvokeHandler)
+	program = appendRouteProgramUvarint(program, uint64(fanoutFork.BaseOffset))
+	program = appendRouteProgramUvarint(program, uint64(count))
+	return append(program, body...), nil
+}
+
+func (c *routeProgramCompiler) compileFork(fanoutFork *FanoutFork) ([]byte, error) {
+	switch fanoutFork.LogicType {
+	case LogicTypePrefixMatching:
+		return c.compilePrefixMatching(fanoutFork)
+	case LogicTypeFuzzyMatching:
+		return c.compileFuzzyMatching(fanoutFork)
+	case LogicTypeGetParameter:
+		return c.compileGetParameter(fanoutFork)
+	case LogicTypeInvokeHandler:
+		return c.compileInvokeHandler(fanoutFork)
+	}
+	return nil, fmt.Errorf("unknown logic type: %v (%v)", fanoutFork.LogicType, fanoutFork.CoveredTerminals)
+}
+
+// makeCodeRouteProgramLiteral return string literal of program with 16 bytes per line.
+func makeCodeRouteProgramLiteral(program []byte) string {
+	if 0 == len(program) {
+		return "\"\""
+	}
+	var lines []string
+	for len(program) > 0 {
+		n := 16
+		if n > len(program) {
+			n = len(program)
+		}
+		var b strings.Builder
+		b.WriteByte('"')
+		for _, ch := range program[:n] {
+			fmt.Fprintf(&b, "\\x%02x", ch)
+		}
+		b.WriteByte('"')
+		lines = append(lines, b.String())
+		program = program[n:]
+	}
+	return strings.Join(lines, " +\n")
+}
+
+func (inst *CodeGenerateInstance) compileRouteProgram() (c *routeProgramCompiler, program []byte, err error) {
+	c = newRouteProgramCompiler(inst)
+	if nil != inst.rootFanoutFork {
+		if program, err = c.compileFork(inst.rootFanoutFork); nil != err {
+			return nil, nil, err
+		}
+	}
+	return
+}
+
+// makeRouteProgramMethodCode generate route method which run route program
+// and handler table of call sites with given handler type.
+func (inst *CodeGenerateInstance) makeRouteProgramMethodCode(c *routeProgramCompiler, handlerTypeName, routeMethodName string) string {
+	resultIdentExpr := inst.makeRouteHitExpression(inst.NamePrefix + "RouteIdent(state.ident)")
+	var handlerFuncs string
+	for _, invokeCode := range c.invokeHandlerCodes {
+		handlerFuncs += strings.TrimSuffix(makeCodeFuncRouteProgramHandler(inst.NamePrefix, inst.helperSuffix(), inst.ReceiverName, handlerTypeName, c.slotCount, strings.TrimSuffix(invokeCode, "\n")), "\n")
+	}
+	return makeCodeMethodRouteProgramEnterance(inst.NamePrefix, inst.helperSuffix(), inst.ReceiverName, handlerTypeName, routeMethodName, c.slotCount, resultIdentExpr) +
+		makeCodeVarRouteProgramHandlers(inst.NamePrefix, inst.helperSuffix(), handlerTypeName, c.slotCount, strings.TrimSuffix(handlerFuncs, "\n"))
+}
+
+// writeRouteProgram write route program, the interpreter and route method.
+func (inst *CodeGenerateInstance) writeRouteProgram() (err error) {
+	c, program, err := inst.compileRouteProgram()
+	if nil != err {
+		return
+	}
+	codeText := makeCodeConstRouteProgramCode(inst.helperSuffix(), makeCodeRouteProgramLiteral(program)) +
+		makeCodeRouteProgramInterpreter(inst.helperSuffix(), inst.runtimeHelperSuffix())
+	if inst.UseHandlerInterface {
+		codeText += inst.generateHandlerInterfaceCode()
+	}
+	codeText += inst.makeRouteProgramMethodCode(c, inst.routeLogicTypeName(), inst.RouteMethodName)
+	_, err = inst.codeBuf.WriteString(codeText)
+	return
+}
@@ -0,0 +1,49 @@
+package httproutegen
+
+import (
+	"bytes"
+	"io"
+)
+
+var runtimeIntegerTypes = []string{"int32", "uint32", "int64", "uint64"}
+
+func makeCodeRuntime(packageName string) string {
+	codeText := generatedCodeIndicatorLine +
+		"package " + packageName + "\n\n" +
+		makeCodeModuleImports([]string{"\"errors\""}) +
+		makeCodeErrFragmentSmallerThanExpect("") +
+		makeCodeFunctionComputePrefixMatching32("") +
+		makeCodeFunctionComputePrefixMatching64("") +
+		makeCodeMethodExtractStringBuiltInR01NoSlash("") +
+		makeCodeMethodExtractIntBuiltInR01("", "32") +
+		makeCodeMethodExtractIntBuiltInR01("", "64")
+	for _, typeName := range runtimeIntegerTypes {
+		codeText += makeCodeMethodExtractUIntBuiltInR02("", typeTitleOfIntegerType(typeName), typeName)
+	}
+	codeText += makeCodeSupportConstantsExtractHexIntBuiltInR03("")
+	for _, typeName := range runtimeIntegerTypes {
+		codeText += makeCodeMethodExtractHexIntBuiltInR03("", typeTitleOfIntegerType(typeName), typeName)
+	}
+	return codeText
+}
+
+// GenerateRuntimeTo write formatted built-in helpers shared by route methods
+// generated with UseSharedRuntime into given writer.
+// The content only depends on package name so one runtime file serves all routers of package.
+func GenerateRuntimeTo(w io.Writer, packageName string) (err error) {
+	codeText, err := formatCode([]byte(makeCodeRuntime(packageName)))
+	if nil != err {
+		return
+	}
+	_, err = w.Write(codeText)
+	return
+}
+
+// GenerateRuntimeFile write formatted built-in helpers into given file atomically.
+func GenerateRuntimeFile(runtimeFilePath, packageName string) (err error) {
+	var buf bytes.Buffer
+	if err = GenerateRuntimeTo(&buf, packageName); nil != err {
+		return
+	}
+	return writeFileAtomically(runtimeFilePath, buf.Bytes())
+}
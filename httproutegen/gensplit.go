@@ -0,0 +1,129 @@
+package httproutegen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// routeFunctionScope keep variables required by route method or route function being generated.
+type routeFunctionScope struct {
+	useDigest32 bool
+	useDigest64 bool
+}
+
+func (scope *routeFunctionScope) usePrefixMatching(use64 bool) {
+	if use64 {
+		scope.useDigest64 = true
+	} else {
+		scope.useDigest32 = true
+	}
+}
+
+func (scope *routeFunctionScope) merge(other *routeFunctionScope) {
+	scope.useDigest32 = scope.useDigest32 || other.useDigest32
+	scope.useDigest64 = scope.useDigest64 || other.useDigest64
+}
+
+func (scope *routeFunctionScope) makeVariableDeclarations() (result string) {
+	if scope.useDigest32 {
+		result = "var digest32 uint32\n"
+	}
+	if scope.useDigest64 {
+		result += "var digest64 uint64\n"
+	}
+	return
+}
+
+// routeFunctionSet collect route functions split from route method of a handler type.
+type routeFunctionSet struct {
+	handlerTypeName string
+	routeMethodName string
+	names           map[string]bool
+	subTreeCount    int
+	codeText        string
+}
+
+func newRouteFunctionSet(handlerTypeName, routeMethodName string) *routeFunctionSet {
+	return &routeFunctionSet{
+		handlerTypeName: handlerTypeName,
+		routeMethodName: routeMethodName,
+		names:           make(map[string]bool),
+	}
+}
+
+// makeName return unused name of route function with given suffix.
+func (funcSet *routeFunctionSet) makeName(suffix string) string {
+	funcName := funcSet.routeMethodName + suffix
+	for idx := 2; funcSet.names[funcName]; idx++ {
+		funcName = funcSet.routeMethodName + suffix + strconv.FormatInt(int64(idx), 10)
+	}
+	funcSet.names[funcName] = true
+	return funcName
+}
+
+// visibleParameters return parameters extracted by enclosing forks, shadowed ones are skipped.
+func (inst *CodeGenerateInstance) visibleParameters() (params []routeParameter) {
+	seen := make(map[string]bool)
+	for idx := len(inst.paramScope) - 1; idx >= 0; idx-- {
+		param := inst.paramScope[idx]
+		if seen[param.name] {
+			continue
+		}
+		seen[param.name] = true
+		params = append([]routeParameter{param}, params...)
+	}
+	return
+}
+
+// isReturnAtEndOfCodeBlock check if the last top level statement of given code is a return statement.
+// Indent of generated code is not normalized yet so nesting is tracked by braces.
+func isReturnAtEndOfCodeBlock(codeText string) bool {
+	depth := 0
+	lastStatement := ""
+	for _, l := range strings.Split(codeText, "\n") {
+		l = strings.TrimSpace(l)
+		if ("" == l) || strings.HasPrefix(l, "//") {
+			continue
+		}
+		if 0 == depth {
+			lastStatement = l
+		}
+		depth += strings.Count(l, "{") - strings.Count(l, "}")
+	}
+	return (0 == depth) && strings.HasPrefix(lastStatement, "return ")
+}
+
+// generateSplittableFanoutCode generate routing code of sub-fork. The code is
+// placed into its own route function if the sub-fork is tip of area and
+// SplitAreas is set, or the code is longer than SplitLineLimit.
+func (inst *CodeGenerateInstance) generateSplittableFanoutCode(fanoutFork, subFork *FanoutFork) string {
+	if (inst.inlineDepth > 0) || (nil == inst.routeFuncs) || (!inst.SplitAreas && (inst.SplitLineLimit <= 0)) {
+		return inst.generateFanoutCode(subFork)
+	}
+	parentScope := inst.funcScope
+	scope := &routeFunctionScope{}
+	inst.funcScope = scope
+	codeText := inst.generateFanoutCode(subFork)
+	inst.funcScope = parentScope
+	var funcName string
+	if inst.SplitAreas && (subFork.AreaName != fanoutFork.AreaName) && subFork.IsTipAreaFork() {
+		funcName = inst.routeFuncs.makeName(subFork.AreaName)
+	} else if (inst.SplitLineLimit > 0) && (strings.Count(codeText, "\n") > inst.SplitLineLimit) {
+		inst.routeFuncs.subTreeCount++
+		funcName = inst.routeFuncs.makeName("Sub" + strconv.FormatInt(int64(inst.routeFuncs.subTreeCount), 10))
+	} else {
+		parentScope.merge(scope)
+		return codeText
+	}
+	var paramDecls, paramArgs string
+	for _, param := range inst.visibleParameters() {
+		paramDecls += ", " + param.name + " " + param.varType
+		paramArgs += ", " + param.name
+	}
+	routingLogicCode := scope.makeVariableDeclarations() + cleanupCodeBlock(codeText, true)
+	if !isReturnAtEndOfCodeBlock(codeText) {
+		routingLogicCode += "\n\treturn " + inst.NamePrefix + "RouteNone, nil"
+	}
+	inst.routeFuncs.codeText += makeCodeMethodRouteFunction(inst.NamePrefix, inst.ReceiverName, inst.routeFuncs.handlerTypeName, funcName, paramDecls, routingLogicCode)
+	return makeCodeBlockInvokeRouteFunction(inst.NamePrefix, inst.ReceiverName, funcName, paramArgs)
+}
@@ -0,0 +1,153 @@
+package httproutegen
+
+import (
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HandlerSignatureMismatch represent a handler method which does not fit the call in generated route method.
+// ActualSignature is empty if the method is missing.
+type HandlerSignatureMismatch struct {
+	Target          *RouteTarget `json:"target"`
+	HandlerName     string       `json:"handler_name"`
+	ExpectSignature string       `json:"expect_signature"`
+	ActualSignature string       `json:"actual_signature,omitempty"`
+	Message         string       `json:"message"`
+}
+
+func (m *HandlerSignatureMismatch) String() string {
+	text := m.HandlerName + " of " + m.Target.Pattern + ": " + m.Message + ", expect: " + m.ExpectSignature
+	if "" != m.ActualSignature {
+		text += ", have: " + m.ActualSignature
+	}
+	return text
+}
+
+// LoadHandlerType type-check Go package in given folder and find named handler type.
+// Files with base name in excludeFileNames (eg: previously generated route code) are skipped.
+// Type errors which are not about the handler type (eg: reference to skipped files) are ignored.
+func LoadHandlerType(packageFolderPath, handlerTypeName string, excludeFileNames []string) (handlerType *types.Named, err error) {
+	buildPkg, err := build.ImportDir(packageFolderPath, 0)
+	if nil != err {
+		return
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, fileName := range buildPkg.GoFiles {
+		excluded := false
+		for _, n := range excludeFileNames {
+			if n == fileName {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(packageFolderPath, fileName), nil, 0)
+		if nil != err {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) {},
+	}
+	pkg, _ := conf.Check(buildPkg.ImportPath, fset, files, nil)
+	if nil == pkg {
+		return nil, &ErrHandlerTypeNotFound{
+			PackageFolderPath: packageFolderPath,
+			HandlerTypeName:   handlerTypeName,
+		}
+	}
+	typeName, ok := pkg.Scope().Lookup(handlerTypeName).(*types.TypeName)
+	if !ok {
+		return nil, &ErrHandlerTypeNotFound{
+			PackageFolderPath: packageFolderPath,
+			HandlerTypeName:   handlerTypeName,
+		}
+	}
+	if handlerType, ok = typeName.Type().(*types.Named); !ok {
+		return nil, &ErrHandlerTypeNotFound{
+			PackageFolderPath: packageFolderPath,
+			HandlerTypeName:   handlerTypeName,
+		}
+	}
+	return handlerType, nil
+}
+
+// expectHandlerParameterTypes return type names of parameters passed to handler of given target.
+func expectHandlerParameterTypes(target *RouteTarget) (result []string) {
+	result = []string{"net/http.ResponseWriter", "*net/http.Request", "int"}
+	for _, param := range target.Parameters {
+		result = append(result, param.Type)
+	}
+	return
+}
+
+func makeExpectHandlerSignature(target *RouteTarget) string {
+	paramDecls := []string{"w http.ResponseWriter", "req *http.Request", "pathOffset int"}
+	for _, param := range target.Parameters {
+		paramDecls = append(paramDecls, param.Name+" "+param.Type)
+	}
+	return "func(" + strings.Join(paramDecls, ", ") + ")"
+}
+
+func checkHandlerMethod(handlerType *types.Named, target *RouteTarget, handlerName string) *HandlerSignatureMismatch {
+	mismatch := &HandlerSignatureMismatch{
+		Target:          target,
+		HandlerName:     handlerName,
+		ExpectSignature: makeExpectHandlerSignature(target),
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(handlerType), true, handlerType.Obj().Pkg(), handlerName)
+	methodObj, ok := obj.(*types.Func)
+	if !ok {
+		mismatch.Message = "method not found on " + handlerType.Obj().Name()
+		return mismatch
+	}
+	sig := methodObj.Type().(*types.Signature)
+	mismatch.ActualSignature = types.TypeString(sig, func(pkg *types.Package) string {
+		if pkg == handlerType.Obj().Pkg() {
+			return ""
+		}
+		return pkg.Name()
+	})
+	expectTypes := expectHandlerParameterTypes(target)
+	if sig.Variadic() {
+		mismatch.Message = "variadic handler method is not supported"
+		return mismatch
+	}
+	if sig.Params().Len() != len(expectTypes) {
+		mismatch.Message = "parameter count mismatch"
+		return mismatch
+	}
+	for idx, expectType := range expectTypes {
+		param := sig.Params().At(idx)
+		if t := types.TypeString(param.Type(), nil); t != expectType {
+			mismatch.Message = "parameter #" + strconv.Itoa(idx+1) + " (" + param.Name() + ") has type " + t + " instead of " + expectType
+			return mismatch
+		}
+	}
+	return nil
+}
+
+// CheckHandlerSignatures confirm every handler method of given targets exists on
+// handler type with the parameters in the order and types passed by generated route method.
+func CheckHandlerSignatures(handlerType *types.Named, targets []*RouteTarget) (mismatches []*HandlerSignatureMismatch) {
+	for _, target := range targets {
+		for _, handlerName := range target.HandlerNames() {
+			if mismatch := checkHandlerMethod(handlerType, target, handlerName); nil != mismatch {
+				mismatches = append(mismatches, mismatch)
+			}
+		}
+	}
+	return
+}
@@ -2,7 +2,6 @@ package httproutegen
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strings"
 )
@@ -89,7 +88,7 @@ func (hn *HandlerNames) rebuildInvokeOrder() {
 	hn.InvokeProfiles = invokeProfiles
 }
 
-func (hn *HandlerNames) expandHandlerName(n string) (string, bool) {
+func (hn *HandlerNames) expandHandlerName(n, routeIdent string, diagnostics Diagnostics) (string, bool) {
 	if "" == n {
 		return n, false
 	}
@@ -110,7 +109,8 @@ func (hn *HandlerNames) expandHandlerName(n string) (string, bool) {
 		case "=options":
 			n = hn.OptionsHandler
 		default:
-			log.Printf("WARN: unknown handler name assignment: %v", n)
+			reportDiagnostic(diagnostics, DiagnosticWarning, DiagnosticUnknownHandlerAssignment, routeIdent,
+				"unknown handler name assignment is dropped: %v", n)
 			n = ""
 		}
 		return n, true
@@ -118,35 +118,35 @@ func (hn *HandlerNames) expandHandlerName(n string) (string, bool) {
 	return n, false
 }
 
-func (hn *HandlerNames) expandNames() {
+func (hn *HandlerNames) expandNames(routeIdent string, diagnostics Diagnostics) {
 	runExpand := true
 	remainExpandCycle := 6
 	for runExpand && (remainExpandCycle > 0) {
 		runExpand = false
 		remainExpandCycle--
 		var expanded bool
-		hn.GetHandler, expanded = hn.expandHandlerName(hn.GetHandler)
+		hn.GetHandler, expanded = hn.expandHandlerName(hn.GetHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.HeadHandler, expanded = hn.expandHandlerName(hn.HeadHandler)
+		hn.HeadHandler, expanded = hn.expandHandlerName(hn.HeadHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.PostHandler, expanded = hn.expandHandlerName(hn.PostHandler)
+		hn.PostHandler, expanded = hn.expandHandlerName(hn.PostHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.PutHandler, expanded = hn.expandHandlerName(hn.PutHandler)
+		hn.PutHandler, expanded = hn.expandHandlerName(hn.PutHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.PatchHandler, expanded = hn.expandHandlerName(hn.PatchHandler)
+		hn.PatchHandler, expanded = hn.expandHandlerName(hn.PatchHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.DeleteHandler, expanded = hn.expandHandlerName(hn.DeleteHandler)
+		hn.DeleteHandler, expanded = hn.expandHandlerName(hn.DeleteHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
-		hn.OptionsHandler, expanded = hn.expandHandlerName(hn.OptionsHandler)
+		hn.OptionsHandler, expanded = hn.expandHandlerName(hn.OptionsHandler, routeIdent, diagnostics)
 		runExpand = runExpand || expanded
 	}
 }
 
-func (hn *HandlerNames) cleanup() {
+func (hn *HandlerNames) cleanup(routeIdent string, diagnostics Diagnostics) {
 	if nil == hn {
 		return
 	}
-	hn.expandNames()
+	hn.expandNames(routeIdent, diagnostics)
 	hn.rebuildInvokeOrder()
 }
 
@@ -0,0 +1,320 @@
+package httproutegen
+
+import (
+	"strconv"
+)
+
+// LintFindingKind is the kind of problem found by route lint.
+type LintFindingKind int
+
+// Kinds of lint findings.
+const (
+	LintAmbiguousPatterns LintFindingKind = iota
+	LintSwallowedLiteral
+	LintMisrouted
+	LintUnreachable
+)
+
+func (k LintFindingKind) String() string {
+	switch k {
+	case LintAmbiguousPatterns:
+		return "AmbiguousPatterns"
+	case LintSwallowedLiteral:
+		return "SwallowedLiteral"
+	case LintMisrouted:
+		return "Misrouted"
+	case LintUnreachable:
+		return "Unreachable"
+	}
+	return "LintFindingKind(" + strconv.FormatInt(int64(k), 10) + ")"
+}
+
+// LintFinding represent one problem found by route lint.
+// Other is the route target which takes the example path if available.
+type LintFinding struct {
+	Kind        LintFindingKind `json:"kind"`
+	Target      *RouteTarget    `json:"target"`
+	Other       *RouteTarget    `json:"other,omitempty"`
+	ExamplePath string          `json:"example_path,omitempty"`
+	Message     string          `json:"message"`
+}
+
+func describeLintTarget(target *RouteTarget) string {
+	return target.YAMLPath + " (" + target.Pattern + ")"
+}
+
+func (f *LintFinding) String() string {
+	text := f.Kind.String() + ": " + describeLintTarget(f.Target)
+	if nil != f.Other {
+		text += " and " + describeLintTarget(f.Other)
+	}
+	text += ": " + f.Message
+	if "" != f.ExamplePath {
+		text += ", example: " + f.ExamplePath
+	}
+	return text
+}
+
+// isLintPathByte check if given byte is used when searching example paths.
+func isLintPathByte(b byte) bool {
+	if (b <= 0x20) || (b >= 0x7F) {
+		return false
+	}
+	switch b {
+	case '?', '#', '%':
+		return false
+	}
+	return true
+}
+
+// patternAutomaton is a NFA accepts paths fully matching symbols of route target.
+// State 2k is before symbol k, state 2k+1 is within sequence of symbol k and
+// state 2n is the final state.
+type patternAutomaton struct {
+	symbols []Symbol
+}
+
+func (a *patternAutomaton) finalState() int {
+	return len(a.symbols) * 2
+}
+
+func (a *patternAutomaton) closure(state int) []int {
+	if (state & 1) == 1 {
+		return []int{state, state + 1}
+	}
+	return []int{state}
+}
+
+func (a *patternAutomaton) accept(state int) bool {
+	for _, s := range a.closure(state) {
+		if s == a.finalState() {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *patternAutomaton) step(state int, ch byte) (result []int) {
+	for _, s := range a.closure(state) {
+		if s == a.finalState() {
+			continue
+		}
+		sym := a.symbols[s/2]
+		switch {
+		case sym.Type == SymbolTypeByte:
+			if ((s & 1) == 0) && (sym.ByteValue == ch) {
+				result = append(result, s+2)
+			}
+		case sym.Type == SymbolTypeSequence:
+			if sym.SequenceValue.ByteMap.HasByte(ch) {
+				result = append(result, s|1)
+			}
+		}
+	}
+	return
+}
+
+type lintSearchNode struct {
+	state1, state2 int
+	parent         *lintSearchNode
+	ch             byte
+}
+
+func (n *lintSearchNode) path() string {
+	var b []byte
+	for node := n; nil != node.parent; node = node.parent {
+		b = append(b, node.ch)
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return "/" + string(b)
+}
+
+// findCommonPath search shortest path fully matching both of given targets.
+func findCommonPath(target1, target2 *RouteTarget) (examplePath string, found bool) {
+	a1 := &patternAutomaton{symbols: target1.Symbols}
+	a2 := &patternAutomaton{symbols: target2.Symbols}
+	visited := make(map[[2]int]bool)
+	queue := []*lintSearchNode{{}}
+	visited[[2]int{0, 0}] = true
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if a1.accept(node.state1) && a2.accept(node.state2) {
+			return node.path(), true
+		}
+		for ch := 0; ch < 0x80; ch++ {
+			if !isLintPathByte(byte(ch)) {
+				continue
+			}
+			for _, s1 := range a1.step(node.state1, byte(ch)) {
+				for _, s2 := range a2.step(node.state2, byte(ch)) {
+					k := [2]int{s1, s2}
+					if visited[k] {
+						continue
+					}
+					visited[k] = true
+					queue = append(queue, &lintSearchNode{
+						state1: s1,
+						state2: s2,
+						parent: node,
+						ch:     byte(ch),
+					})
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func haveCommonMethod(target1, target2 *RouteTarget) bool {
+	for _, invokeProfile := range target1.Route.HandlerProfile.InvokeProfiles {
+		if "" != target2.HandlerNameOfMethod(invokeProfile.RequestMethod) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintSwallowedLiteral find sequences followed by a literal byte or another sequence which shares
+// bytes allowed by the sequence. Extract functions are greedy so such bytes are consumed as part
+// of the former parameter.
+func lintSwallowedLiteral(target *RouteTarget) (findings []*LintFinding) {
+	for idx, sym := range target.Symbols {
+		if (sym.Type != SymbolTypeSequence) || (idx+1 >= len(target.Symbols)) {
+			continue
+		}
+		nextSym := target.Symbols[idx+1]
+		var message string
+		switch nextSym.Type {
+		case SymbolTypeByte:
+			if !sym.SequenceValue.ByteMap.HasByte(nextSym.ByteValue) {
+				continue
+			}
+			message = "literal " + strconv.QuoteRune(rune(nextSym.ByteValue)) + " after parameter " + sym.SequenceVarName + " is consumed by the parameter"
+		case SymbolTypeSequence:
+			commonByte, found := sym.SequenceValue.ByteMap.FirstCommonByte(&nextSym.SequenceValue.ByteMap)
+			if !found {
+				continue
+			}
+			message = "parameter " + nextSym.SequenceVarName + " directly follows parameter " + sym.SequenceVarName + " and bytes allowed by both such as " + strconv.QuoteRune(rune(commonByte)) + " are consumed by parameter " + sym.SequenceVarName
+		default:
+			continue
+		}
+		finding := &LintFinding{
+			Kind:    LintSwallowedLiteral,
+			Target:  target,
+			Message: message,
+		}
+		if samplePaths := target.MakeSamplePaths(); len(samplePaths) > 0 {
+			finding.ExamplePath = samplePaths[0].Path
+		}
+		findings = append(findings, finding)
+	}
+	return
+}
+
+// LintRouteTargets find problems in patterns of route targets.
+// It reports literals swallowed by parameters and pairs of targets which match
+// the same request path with at least one common request method.
+func LintRouteTargets(targets []*RouteTarget) (findings []*LintFinding) {
+	for idx, target := range targets {
+		findings = append(findings, lintSwallowedLiteral(target)...)
+		for _, other := range targets[idx+1:] {
+			if !haveCommonMethod(target, other) {
+				continue
+			}
+			if examplePath, found := findCommonPath(target, other); found {
+				findings = append(findings, &LintFinding{
+					Kind:        LintAmbiguousPatterns,
+					Target:      target,
+					Other:       other,
+					ExamplePath: examplePath,
+					Message:     "both patterns fully match the same path",
+				})
+			}
+		}
+	}
+	return
+}
+
+func collectInvokeRouteEntries(fanoutFork *FanoutFork, result map[*RouteEntry]bool) {
+	if fanoutFork.LogicType == LogicTypeInvokeHandler {
+		result[fanoutFork.InvokeHandlerFanout.Route] = true
+	}
+	for _, childFork := range fanoutFork.ChildForks {
+		collectInvokeRouteEntries(childFork, result)
+	}
+}
+
+func findTargetOfHandler(targets []*RouteTarget, handlerName string) *RouteTarget {
+	for _, target := range targets {
+		for _, n := range target.HandlerNames() {
+			if n == handlerName {
+				return target
+			}
+		}
+	}
+	return nil
+}
+
+func isPathMatchingOtherTarget(targets []*RouteTarget, target *RouteTarget, reqPath string) bool {
+	for _, other := range targets {
+		if other == target {
+			continue
+		}
+		if _, ok := other.MatchPath(reqPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LintFanoutInstance route sample paths of each target with interpreter of expanded
+// fanout instance and report targets which cannot be reached or are routed to other
+// targets. Targets must be collected from the same root route entry of fanout instance.
+func LintFanoutInstance(targets []*RouteTarget, fanoutInstance *FanoutInstance) (findings []*LintFinding) {
+	reachableEntries := make(map[*RouteEntry]bool)
+	if nil != fanoutInstance.RootFanoutFork {
+		collectInvokeRouteEntries(fanoutInstance.RootFanoutFork, reachableEntries)
+	}
+	router := NewRouter(fanoutInstance)
+	for _, target := range targets {
+		if !reachableEntries[target.Route] {
+			findings = append(findings, &LintFinding{
+				Kind:    LintUnreachable,
+				Target:  target,
+				Message: "no handler invocation is generated for this route",
+			})
+			continue
+		}
+		for _, samplePath := range target.MakeSamplePaths() {
+			if isPathMatchingOtherTarget(targets, target, samplePath.Path) {
+				continue
+			}
+			methodName := target.Route.HandlerProfile.InvokeProfiles[0].RequestMethod
+			expectHandlerName := target.HandlerNameOfMethod(methodName)
+			result, err := router.Route(methodName, samplePath.Path)
+			if (nil == err) && (nil != result) && (result.HandlerName == expectHandlerName) {
+				continue
+			}
+			finding := &LintFinding{
+				Kind:        LintMisrouted,
+				Target:      target,
+				ExamplePath: samplePath.Path,
+			}
+			switch {
+			case nil != err:
+				finding.Message = "routing failed: " + err.Error()
+			case (nil != result) && ("" != result.HandlerName):
+				finding.Other = findTargetOfHandler(targets, result.HandlerName)
+				finding.Message = methodName + " request is routed to handler " + result.HandlerName
+			case nil != result:
+				finding.Message = methodName + " request is routed to " + result.RouteIdent
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return
+}
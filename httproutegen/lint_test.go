@@ -0,0 +1,57 @@
+package httproutegen
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// loadTestRouteConfig load route configuration from given YAML text.
+func loadTestRouteConfig(t *testing.T, configText string) *RouteEntry {
+	fp, err := ioutil.TempFile("", "httproutegen-route-*.yaml")
+	if nil != err {
+		t.Fatalf("cannot create route configuration file: %v", err)
+	}
+	defer os.Remove(fp.Name())
+	_, err = fp.WriteString(configText)
+	if closeErr := fp.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		t.Fatalf("cannot write route configuration file: %v", err)
+	}
+	rootRouteEntry, err := LoadYAML(fp.Name())
+	if nil != err {
+		t.Fatalf("cannot load route configuration: %v", err)
+	}
+	return rootRouteEntry
+}
+
+func TestLintSwallowedLiteral(t *testing.T) {
+	testCases := []struct {
+		component    string
+		findingCount int
+	}{
+		{"x/{a-z, name string}{0-9, num int32}", 0},
+		{"x/{a-z0-9, name string}{0-9, num int32}", 1},
+		{"x/{a-z, name string}-{0-9, num int32}", 0},
+		{"x/{a-z\\-, name string}-{0-9, num int32}", 1},
+	}
+	for _, tc := range testCases {
+		rootRouteEntry := loadTestRouteConfig(t, "route:\n- c: '"+tc.component+"'\n  handler:\n    get: \"target\"\n")
+		targets, err := CollectRouteTargets(rootRouteEntry)
+		if nil != err {
+			t.Fatalf("%s: cannot collect route targets: %v", tc.component, err)
+		}
+		findings := LintRouteTargets(targets)
+		if len(findings) != tc.findingCount {
+			t.Errorf("%s: expect %d finding(s) but have %v", tc.component, tc.findingCount, findings)
+			continue
+		}
+		for _, finding := range findings {
+			if LintSwallowedLiteral != finding.Kind {
+				t.Errorf("%s: unexpected finding: %v", tc.component, finding)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package httproutegen
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// OpenAPIVersion is the version of OpenAPI specification documents are generated with.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIInfo represent info object of OpenAPI document.
+type OpenAPIInfo struct {
+	Title   string `yaml:"title" json:"title"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// OpenAPISchema represent schema object of parameter.
+type OpenAPISchema struct {
+	Type    string `yaml:"type,omitempty" json:"type,omitempty"`
+	Format  string `yaml:"format,omitempty" json:"format,omitempty"`
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Minimum *int64 `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	GoType  string `yaml:"x-go-type,omitempty" json:"x-go-type,omitempty"`
+}
+
+// OpenAPIParameter represent parameter object of path item.
+type OpenAPIParameter struct {
+	Name     string         `yaml:"name" json:"name"`
+	In       string         `yaml:"in" json:"in"`
+	Required bool           `yaml:"required" json:"required"`
+	Schema   *OpenAPISchema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// OpenAPIResponse represent response object of operation.
+type OpenAPIResponse struct {
+	Description string `yaml:"description" json:"description"`
+}
+
+// OpenAPIOperation represent operation object of path item.
+type OpenAPIOperation struct {
+	OperationID string                      `yaml:"operationId,omitempty" json:"operationId,omitempty"`
+	Tags        []string                    `yaml:"tags,omitempty" json:"tags,omitempty"`
+	HandlerName string                      `yaml:"x-handler-name,omitempty" json:"x-handler-name,omitempty"`
+	Parameters  []*OpenAPIParameter         `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Responses   map[string]*OpenAPIResponse `yaml:"responses,omitempty" json:"responses,omitempty"`
+}
+
+// OpenAPIPathItem represent path item object of OpenAPI document.
+type OpenAPIPathItem struct {
+	Get        *OpenAPIOperation   `yaml:"get,omitempty" json:"get,omitempty"`
+	Head       *OpenAPIOperation   `yaml:"head,omitempty" json:"head,omitempty"`
+	Post       *OpenAPIOperation   `yaml:"post,omitempty" json:"post,omitempty"`
+	Put        *OpenAPIOperation   `yaml:"put,omitempty" json:"put,omitempty"`
+	Patch      *OpenAPIOperation   `yaml:"patch,omitempty" json:"patch,omitempty"`
+	Delete     *OpenAPIOperation   `yaml:"delete,omitempty" json:"delete,omitempty"`
+	Options    *OpenAPIOperation   `yaml:"options,omitempty" json:"options,omitempty"`
+	Parameters []*OpenAPIParameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+func (item *OpenAPIPathItem) operationRef(methodName string) **OpenAPIOperation {
+	switch strings.ToUpper(methodName) {
+	case http.MethodGet:
+		return &item.Get
+	case http.MethodHead:
+		return &item.Head
+	case http.MethodPost:
+		return &item.Post
+	case http.MethodPut:
+		return &item.Put
+	case http.MethodPatch:
+		return &item.Patch
+	case http.MethodDelete:
+		return &item.Delete
+	case http.MethodOptions:
+		return &item.Options
+	}
+	return nil
+}
+
+// Operation return operation of given request method.
+func (item *OpenAPIPathItem) Operation(methodName string) *OpenAPIOperation {
+	if ref := item.operationRef(methodName); nil != ref {
+		return *ref
+	}
+	return nil
+}
+
+// OpenAPIDocument represent root object of OpenAPI document.
+type OpenAPIDocument struct {
+	OpenAPI string                      `yaml:"openapi" json:"openapi"`
+	Info    OpenAPIInfo                 `yaml:"info" json:"info"`
+	Paths   map[string]*OpenAPIPathItem `yaml:"paths" json:"paths"`
+}
+
+func isDecimalByteMap(m *ByteMapper) bool {
+	for _, r := range m.ByteRanges() {
+		if (r.From < '0') || (r.To > '9') {
+			if (r.From != '-') || (r.To != '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func makeOpenAPISchema(param *RouteParameter) *OpenAPISchema {
+	seqPart := param.Sequence
+	schema := &OpenAPISchema{
+		GoType: param.Type,
+	}
+	if isDecimalByteMap(&seqPart.ByteMap) {
+		var zero int64
+		switch param.Type {
+		case "int32", "int64":
+			schema.Type = "integer"
+			schema.Format = param.Type
+			return schema
+		case "uint32":
+			schema.Type = "integer"
+			schema.Format = "int64"
+			schema.Minimum = &zero
+			return schema
+		case "uint64":
+			schema.Type = "integer"
+			schema.Minimum = &zero
+			return schema
+		}
+	}
+	schema.Type = "string"
+	schema.Pattern = "^" + seqPart.ByteMap.RegexpCharClass() + "+$"
+	return schema
+}
+
+func makeOpenAPIOperationID(handlerName, methodName string, usedOperationIDs map[string]bool) string {
+	operationID := handlerName
+	if usedOperationIDs[operationID] {
+		m := []rune(strings.ToLower(methodName))
+		m[0] = unicode.ToTitle(m[0])
+		operationID = handlerName + string(m)
+		for serial := 2; usedOperationIDs[operationID]; serial++ {
+			operationID = fmt.Sprintf("%s%s%d", handlerName, string(m), serial)
+		}
+	}
+	usedOperationIDs[operationID] = true
+	return operationID
+}
+
+// MakeOpenAPIDocument create OpenAPI document from route targets.
+func MakeOpenAPIDocument(title, version string, targets []*RouteTarget) (doc *OpenAPIDocument, err error) {
+	doc = &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:   title,
+			Version: version,
+		},
+		Paths: make(map[string]*OpenAPIPathItem),
+	}
+	usedOperationIDs := make(map[string]bool)
+	templatePatterns := make(map[string]string)
+	for _, target := range targets {
+		if pattern, ok := templatePatterns[target.Template]; ok && (pattern != target.Pattern) {
+			return nil, &ErrConflictConfiguration{
+				Component: target.Route.Ident,
+				Config1:   "pattern=" + pattern,
+				Config2:   "pattern=" + target.Pattern,
+				Message:   "different patterns share path template " + target.Template,
+			}
+		}
+		templatePatterns[target.Template] = target.Pattern
+		pathItem := doc.Paths[target.Template]
+		if nil == pathItem {
+			pathItem = &OpenAPIPathItem{}
+			for _, param := range target.Parameters {
+				pathItem.Parameters = append(pathItem.Parameters, &OpenAPIParameter{
+					Name:     param.Name,
+					In:       "path",
+					Required: true,
+					Schema:   makeOpenAPISchema(param),
+				})
+			}
+			doc.Paths[target.Template] = pathItem
+		}
+		for _, invokeProfile := range target.Route.HandlerProfile.InvokeProfiles {
+			opRef := pathItem.operationRef(invokeProfile.RequestMethod)
+			if nil == opRef {
+				continue
+			}
+			if nil != *opRef {
+				return nil, &ErrConflictConfiguration{
+					Component: target.Route.Ident,
+					Config1:   "template=" + target.Template,
+					Config2:   "method=" + invokeProfile.RequestMethod,
+					Message:   "operation existed on the same path template",
+				}
+			}
+			op := &OpenAPIOperation{
+				OperationID: makeOpenAPIOperationID(invokeProfile.HandlerName, invokeProfile.RequestMethod, usedOperationIDs),
+				HandlerName: invokeProfile.HandlerName,
+				Responses: map[string]*OpenAPIResponse{
+					"default": {
+						Description: "response of " + invokeProfile.HandlerName,
+					},
+				},
+			}
+			if "" != target.Route.AreaName {
+				op.Tags = []string{target.Route.AreaName}
+			}
+			*opRef = op
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,371 @@
+package httproutegen
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LoadOpenAPI get OpenAPI document from YAML or JSON file.
+func LoadOpenAPI(docFilePath string) (doc *OpenAPIDocument, err error) {
+	buf, err := ioutil.ReadFile(docFilePath)
+	if nil != err {
+		return
+	}
+	var docBuf OpenAPIDocument
+	if err = yaml.Unmarshal(buf, &docBuf); nil != err {
+		return
+	}
+	return &docBuf, nil
+}
+
+func parseRegexpCharClassEscape(c string) (ranges []ByteRange, consumed int, err error) {
+	if len(c) < 2 {
+		return nil, 0, errors.New("escape at end of character class")
+	}
+	switch ch := c[1]; ch {
+	case 'd':
+		return []ByteRange{{'0', '9'}}, 2, nil
+	case 'w':
+		return []ByteRange{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}}, 2, nil
+	case 'x':
+		if len(c) < 4 {
+			return nil, 0, errors.New("incomplete hex escape in character class")
+		}
+		v, err := strconv.ParseUint(c[2:4], 16, 8)
+		if nil != err {
+			return nil, 0, err
+		}
+		return []ByteRange{{byte(v), byte(v)}}, 4, nil
+	default:
+		if unicode.IsLetter(rune(ch)) || unicode.IsDigit(rune(ch)) {
+			return nil, 0, fmt.Errorf("unsupported escape in character class: \\%c", ch)
+		}
+		return []ByteRange{{ch, ch}}, 2, nil
+	}
+}
+
+// parseRegexpCharClass convert pattern in form of `^[...]+$` into byte mapper.
+func parseRegexpCharClass(pattern string) (m ByteMapper, err error) {
+	c := strings.TrimPrefix(pattern, "^")
+	c = strings.TrimSuffix(c, "$")
+	if strings.HasSuffix(c, "+") || strings.HasSuffix(c, "*") {
+		c = c[:len(c)-1]
+	}
+	if (len(c) < 2) || (c[0] != '[') || (c[len(c)-1] != ']') {
+		return m, fmt.Errorf("pattern is not a repeated character class: %v", pattern)
+	}
+	c = c[1 : len(c)-1]
+	inverse := false
+	if strings.HasPrefix(c, "^") {
+		inverse = true
+		c = c[1:]
+	}
+	var ranges []ByteRange
+	for len(c) > 0 {
+		var current []ByteRange
+		if c[0] == '\\' {
+			var consumed int
+			if current, consumed, err = parseRegexpCharClassEscape(c); nil != err {
+				return
+			}
+			c = c[consumed:]
+		} else {
+			current = []ByteRange{{c[0], c[0]}}
+			c = c[1:]
+		}
+		if (len(current) == 1) && (len(c) > 1) && (c[0] == '-') {
+			var rangeTo []ByteRange
+			if c[1] == '\\' {
+				var consumed int
+				if rangeTo, consumed, err = parseRegexpCharClassEscape(c[1:]); nil != err {
+					return
+				}
+				c = c[1+consumed:]
+			} else {
+				rangeTo = []ByteRange{{c[1], c[1]}}
+				c = c[2:]
+			}
+			current[0].To = rangeTo[0].To
+		}
+		ranges = append(ranges, current...)
+	}
+	for _, r := range ranges {
+		if (r.From > 127) || (r.To > 127) {
+			return m, fmt.Errorf("byte > 127 is not supported in character class: %v", pattern)
+		}
+	}
+	if inverse {
+		m.enablePrintables()
+	}
+	for _, r := range ranges {
+		if inverse {
+			m.disableByteRange(r.From, r.To)
+		} else {
+			m.enableByteRange(r.From, r.To)
+		}
+	}
+	return m, nil
+}
+
+func makeSequenceRuleFromOpenAPIParameter(param *OpenAPIParameter) (byteMapRule, varType string, err error) {
+	schema := param.Schema
+	if nil == schema {
+		schema = &OpenAPISchema{}
+	}
+	varType = schema.GoType
+	if "" == varType {
+		switch schema.Type {
+		case "integer":
+			if schema.Format == "int32" {
+				varType = "int32"
+			} else {
+				varType = "int64"
+			}
+		default:
+			varType = "string"
+		}
+	}
+	if "" != schema.Pattern {
+		m, parseErr := parseRegexpCharClass(schema.Pattern)
+		if nil != parseErr {
+			return "", "", fmt.Errorf("cannot convert pattern of parameter %v: %v", param.Name, parseErr)
+		}
+		if byteMapRule = m.RuleText(); "" == byteMapRule {
+			return "", "", fmt.Errorf("empty character class for parameter %v: %v", param.Name, schema.Pattern)
+		}
+		return
+	}
+	switch varType {
+	case "string", "[]byte":
+		byteMapRule = "^/"
+	default:
+		byteMapRule = "0-9"
+	}
+	return
+}
+
+// templateLiteralDecoder reverse percent-encoding of literal braces and percent sign in path templates.
+var templateLiteralDecoder = strings.NewReplacer("%7B", "{", "%7b", "{", "%7D", "}", "%7d", "}", "%25", "%")
+
+func escapeComponentLiteral(literal string) string {
+	var b []byte
+	for _, ch := range []byte(templateLiteralDecoder.Replace(literal)) {
+		if (ch == '{') || (ch == '}') || (ch == '\\') {
+			b = append(b, '\\')
+		}
+		b = append(b, ch)
+	}
+	return string(b)
+}
+
+func makeComponentFromOpenAPIPathSegment(segment string, params map[string]*OpenAPIParameter) (component string, err error) {
+	for len(segment) > 0 {
+		idx := strings.IndexByte(segment, '{')
+		if idx < 0 {
+			component += escapeComponentLiteral(segment)
+			break
+		}
+		component += escapeComponentLiteral(segment[:idx])
+		segment = segment[idx+1:]
+		if idx = strings.IndexByte(segment, '}'); idx < 0 {
+			return "", fmt.Errorf("parameter not close: %v", segment)
+		}
+		paramName := segment[:idx]
+		if paramName != makeIdentifierFromText(paramName, false) {
+			component += escapeComponentLiteral("{")
+			continue
+		}
+		segment = segment[idx+1:]
+		param := params[paramName]
+		if nil == param {
+			param = &OpenAPIParameter{
+				Name: paramName,
+			}
+		}
+		byteMapRule, varType, err := makeSequenceRuleFromOpenAPIParameter(param)
+		if nil != err {
+			return "", err
+		}
+		component += "{" + byteMapRule + ", " + paramName + " " + varType + "}"
+	}
+	return
+}
+
+func makeIdentifierFromText(text string, titleFirst bool) string {
+	var b []rune
+	upperNext := titleFirst
+	for _, ch := range text {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			upperNext = len(b) > 0 || titleFirst
+			continue
+		}
+		if upperNext {
+			ch = unicode.ToTitle(ch)
+			upperNext = false
+		}
+		b = append(b, ch)
+	}
+	if (len(b) > 0) && unicode.IsDigit(b[0]) {
+		b = append([]rune{'r'}, b...)
+	}
+	return string(b)
+}
+
+func makeHandlerNameFromOpenAPIOperation(op *OpenAPIOperation, methodName, docPath string) string {
+	if "" != op.HandlerName {
+		return op.HandlerName
+	}
+	if n := makeIdentifierFromText(op.OperationID, false); "" != n {
+		return n
+	}
+	var literalParts []string
+	for _, segment := range strings.Split(docPath, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			literalParts = append(literalParts, segment)
+		}
+	}
+	return strings.ToLower(methodName) + makeIdentifierFromText(strings.Join(literalParts, "-"), true)
+}
+
+func (hn *HandlerNames) setHandlerNameByMethod(methodName, handlerName string) {
+	switch strings.ToUpper(methodName) {
+	case http.MethodGet:
+		hn.GetHandler = handlerName
+	case http.MethodHead:
+		hn.HeadHandler = handlerName
+	case http.MethodPost:
+		hn.PostHandler = handlerName
+	case http.MethodPut:
+		hn.PutHandler = handlerName
+	case http.MethodPatch:
+		hn.PatchHandler = handlerName
+	case http.MethodDelete:
+		hn.DeleteHandler = handlerName
+	case http.MethodOptions:
+		hn.OptionsHandler = handlerName
+	}
+}
+
+type openAPIRouteNode struct {
+	component string
+	handler   *HandlerNames
+	children  []*openAPIRouteNode
+}
+
+func (n *openAPIRouteNode) child(component string) *openAPIRouteNode {
+	for _, childNode := range n.children {
+		if childNode.component == component {
+			return childNode
+		}
+	}
+	childNode := &openAPIRouteNode{
+		component: component,
+	}
+	n.children = append(n.children, childNode)
+	return childNode
+}
+
+func (n *openAPIRouteNode) makeRouteEntries(prefix string) (result []*RouteEntry) {
+	component := n.component
+	if "" != prefix {
+		component = prefix + "/" + component
+	}
+	if (nil == n.handler) && (len(n.children) == 1) {
+		return n.children[0].makeRouteEntries(component)
+	}
+	if nil != n.handler {
+		result = append(result, &RouteEntry{
+			Component:      component,
+			HandlerProfile: n.handler,
+		})
+		for _, childNode := range n.children {
+			result = append(result, childNode.makeRouteEntries(component)...)
+		}
+		return
+	}
+	groupEntry := &RouteEntry{
+		Component: component,
+	}
+	for _, childNode := range n.children {
+		groupEntry.Routes = append(groupEntry.Routes, childNode.makeRouteEntries("")...)
+	}
+	return []*RouteEntry{groupEntry}
+}
+
+func collectOpenAPIPathParameters(pathItem *OpenAPIPathItem, op *OpenAPIOperation) map[string]*OpenAPIParameter {
+	params := make(map[string]*OpenAPIParameter)
+	for _, param := range pathItem.Parameters {
+		if param.In == "path" {
+			params[param.Name] = param
+		}
+	}
+	if nil != op {
+		for _, param := range op.Parameters {
+			if param.In == "path" {
+				params[param.Name] = param
+			}
+		}
+	}
+	return params
+}
+
+// MakeRouteEntryFromOpenAPI create root route entry from paths of OpenAPI document.
+// Paths sharing common leading segments are grouped into nested route entries.
+func MakeRouteEntryFromOpenAPI(doc *OpenAPIDocument) (rootRouteEntry *RouteEntry, err error) {
+	var docPaths []string
+	for docPath := range doc.Paths {
+		docPaths = append(docPaths, docPath)
+	}
+	sort.Strings(docPaths)
+	rootNode := &openAPIRouteNode{}
+	for _, docPath := range docPaths {
+		pathItem := doc.Paths[docPath]
+		handlerNames := &HandlerNames{}
+		var firstOp *OpenAPIOperation
+		for _, methodName := range defaultMethodEvaluateOrder {
+			op := pathItem.Operation(methodName)
+			if nil == op {
+				continue
+			}
+			if nil == firstOp {
+				firstOp = op
+			}
+			handlerNames.setHandlerNameByMethod(methodName, makeHandlerNameFromOpenAPIOperation(op, methodName, docPath))
+		}
+		if nil == firstOp {
+			continue
+		}
+		params := collectOpenAPIPathParameters(pathItem, firstOp)
+		node := rootNode
+		for _, segment := range strings.Split(docPath, "/") {
+			if "" == segment {
+				continue
+			}
+			component, err := makeComponentFromOpenAPIPathSegment(segment, params)
+			if nil != err {
+				return nil, fmt.Errorf("cannot convert path %v: %v", docPath, err)
+			}
+			node = node.child(component)
+		}
+		if node == rootNode {
+			return nil, fmt.Errorf("cannot route root path: %v", docPath)
+		}
+		if nil != node.handler {
+			return nil, fmt.Errorf("duplicated path after conversion: %v", docPath)
+		}
+		node.handler = handlerNames
+	}
+	rootRouteEntry = &RouteEntry{}
+	for _, childNode := range rootNode.children {
+		rootRouteEntry.Routes = append(rootRouteEntry.Routes, childNode.makeRouteEntries("")...)
+	}
+	return rootRouteEntry, nil
+}
@@ -13,16 +13,48 @@ func shouldTrimFromComponent(ch rune) bool {
 	return unicode.IsSpace(ch) || (ch == '/')
 }
 
+// GeneratorOptions represent code generator options declared in route configuration.
+// Empty values are left for command line flags or defaults.
+type GeneratorOptions struct {
+	PackageName          string `yaml:"package,omitempty" json:"package,omitempty"`
+	ReceiverName         string `yaml:"receiver,omitempty" json:"receiver,omitempty"`
+	HandlerTypeName      string `yaml:"type,omitempty" json:"type,omitempty"`
+	RouteMethodName      string `yaml:"methodName,omitempty" json:"method_name,omitempty"`
+	NamePrefix           string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	RuntimeFileName      string `yaml:"runtime,omitempty" json:"runtime,omitempty"`
+	Backend              string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	ObserverMethodName   string `yaml:"observer,omitempty" json:"observer,omitempty"`
+	SwitchMinWidth       int    `yaml:"switchWidth,omitempty" json:"switch_width,omitempty"`
+	BinarySearchMinWidth int    `yaml:"binarySearchWidth,omitempty" json:"binary_search_width,omitempty"`
+	SplitAreas           bool   `yaml:"splitAreas,omitempty" json:"split_areas,omitempty"`
+	SplitLineLimit       int    `yaml:"splitLines,omitempty" json:"split_lines,omitempty"`
+	CountRouteHits       bool   `yaml:"countHits,omitempty" json:"count_hits,omitempty"`
+	TimeRouteHandlers    bool   `yaml:"timeHandlers,omitempty" json:"time_handlers,omitempty"`
+	NoPrefixDigest64     bool   `yaml:"noDigest64,omitempty" json:"no_digest64,omitempty"`
+	UseHandlerInterface  bool   `yaml:"handlerInterface,omitempty" json:"handler_interface,omitempty"`
+}
+
 // RouteEntry represent an entry of route
 type RouteEntry struct {
-	Ident             string        `yaml:"-" json:"component_ident,omitempty"`
-	Component         string        `yaml:"c,omitempty" json:"c,omitempty"`
-	AreaName          string        `yaml:"area,omitempty" json:"area,omitempty"`
-	HandlerProfile    *HandlerNames `yaml:"handler,omitempty" json:"handler,omitempty"`
-	StrictPrefixMatch string        `yaml:"strict-prefix-match,omitempty" json:"strict_prefix_match,omitempty"`
-	StrictMatch       bool          `yaml:"strict-match,omitempty" json:"strict_match,omitempty"`
-	TrailingSlash     bool          `yaml:"trailing-slash,omitempty" json:"trailing_slash,omitempty"`
-	Routes            []*RouteEntry `yaml:"route,omitempty" json:"route,omitempty"`
+	Generator         *GeneratorOptions `yaml:"generator,omitempty" json:"generator,omitempty"`
+	Routers           []*RouterEntry    `yaml:"routers,omitempty" json:"routers,omitempty"`
+	Ident             string            `yaml:"-" json:"component_ident,omitempty"`
+	Component         string            `yaml:"c,omitempty" json:"c,omitempty"`
+	AreaName          string            `yaml:"area,omitempty" json:"area,omitempty"`
+	HandlerProfile    *HandlerNames     `yaml:"handler,omitempty" json:"handler,omitempty"`
+	StrictPrefixMatch string            `yaml:"strict-prefix-match,omitempty" json:"strict_prefix_match,omitempty"`
+	StrictMatch       bool              `yaml:"strict-match,omitempty" json:"strict_match,omitempty"`
+	TrailingSlash     bool              `yaml:"trailing-slash,omitempty" json:"trailing_slash,omitempty"`
+	Routes            []*RouteEntry     `yaml:"route,omitempty" json:"route,omitempty"`
+}
+
+// RouterEntry represent a named router declared in route configuration.
+// OutputFile is relative to the folder of main output file. Routers without
+// OutputFile are generated into main output file.
+type RouterEntry struct {
+	Name       string `yaml:"name" json:"name"`
+	OutputFile string `yaml:"out,omitempty" json:"out,omitempty"`
+	RouteEntry `yaml:",inline"`
 }
 
 func (entry *RouteEntry) makeComponentIdent(parentComponentIdent string) string {
@@ -57,7 +89,7 @@ func (entry *RouteEntry) cleanupAreaName(parentAreaName string) {
 	}
 }
 
-func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaName string) error {
+func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaName string, diagnostics Diagnostics) error {
 	if err := entry.cleanupComponent(parentComponentIdent); nil != err {
 		return err
 	}
@@ -65,6 +97,22 @@ func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaNam
 	entry.cleanupAreaName(parentAreaName)
 	componentIdent := entry.makeComponentIdent(parentComponentIdent)
 	entry.Ident = componentIdent
+	if ("" != parentComponentIdent) && (nil != entry.Generator) {
+		return &ErrConflictConfiguration{
+			Component: componentIdent,
+			Config1:   "generator",
+			Config2:   "c=" + entry.Component,
+			Message:   "generator options are only allowed at top level",
+		}
+	}
+	if ("" != parentComponentIdent) && (len(entry.Routers) > 0) {
+		return &ErrConflictConfiguration{
+			Component: componentIdent,
+			Config1:   "routers",
+			Config2:   "c=" + entry.Component,
+			Message:   "routers are only allowed at top level",
+		}
+	}
 	if ("" != entry.StrictPrefixMatch) && entry.StrictMatch {
 		return &ErrConflictConfiguration{
 			Component: componentIdent,
@@ -73,7 +121,7 @@ func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaNam
 			Message:   "partial-strict-match and fully-strict-match cannot co-exist",
 		}
 	}
-	entry.HandlerProfile.cleanup()
+	entry.HandlerProfile.cleanup(componentIdent, diagnostics)
 	if entry.HandlerProfile.isEmpty() {
 		entry.HandlerProfile = nil
 		if entry.TrailingSlash {
@@ -94,7 +142,51 @@ func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaNam
 		}
 	}
 	for _, childEntry := range entry.Routes {
-		if err := childEntry.verifyConfiguration(componentIdent, entry.AreaName); nil != err {
+		if err := childEntry.verifyConfiguration(componentIdent, entry.AreaName, diagnostics); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (entry *RouteEntry) verifyRouters(diagnostics Diagnostics) error {
+	if ("" != strings.TrimFunc(entry.Component, shouldTrimFromComponent)) || !entry.HandlerProfile.isEmpty() || (len(entry.Routes) > 0) {
+		return &ErrConflictConfiguration{
+			Component: "/",
+			Config1:   "routers",
+			Config2:   "route",
+			Message:   "routes must be declared inside routers",
+		}
+	}
+	seenNames := make(map[string]bool)
+	for _, router := range entry.Routers {
+		router.Name = strings.TrimSpace(router.Name)
+		if "" == router.Name {
+			return &ErrConflictConfiguration{
+				Component: "/",
+				Config1:   "routers",
+				Config2:   "name=",
+				Message:   "router name is required",
+			}
+		}
+		if seenNames[router.Name] {
+			return &ErrConflictConfiguration{
+				Component: "/",
+				Config1:   "routers",
+				Config2:   "name=" + router.Name,
+				Message:   "router name must be unique",
+			}
+		}
+		seenNames[router.Name] = true
+		if len(router.Routers) > 0 {
+			return &ErrConflictConfiguration{
+				Component: "/",
+				Config1:   "routers",
+				Config2:   "name=" + router.Name,
+				Message:   "routers cannot be nested",
+			}
+		}
+		if err := router.verifyConfiguration("", "", diagnostics); nil != err {
 			return err
 		}
 	}
@@ -103,6 +195,12 @@ func (entry *RouteEntry) verifyConfiguration(parentComponentIdent, parentAreaNam
 
 // LoadYAML get route configuration from YAML file
 func LoadYAML(configFilePath string) (routeEntry *RouteEntry, err error) {
+	return LoadYAMLWithDiagnostics(configFilePath, nil)
+}
+
+// LoadYAMLWithDiagnostics get route configuration from YAML file and report
+// warnings found in configuration to given diagnostics.
+func LoadYAMLWithDiagnostics(configFilePath string, diagnostics Diagnostics) (routeEntry *RouteEntry, err error) {
 	buf, err := ioutil.ReadFile(configFilePath)
 	if nil != err {
 		return
@@ -111,8 +209,22 @@ func LoadYAML(configFilePath string) (routeEntry *RouteEntry, err error) {
 	if err = yaml.Unmarshal(buf, &routeEntryBuf); nil != err {
 		return
 	}
-	if err = routeEntryBuf.verifyConfiguration("", ""); nil != err {
+	if len(routeEntryBuf.Routers) > 0 {
+		err = routeEntryBuf.verifyRouters(diagnostics)
+	} else {
+		err = routeEntryBuf.verifyConfiguration("", "", diagnostics)
+	}
+	if nil != err {
 		return
 	}
 	return &routeEntryBuf, nil
 }
+
+// SaveYAML write route configuration into YAML file.
+func SaveYAML(configFilePath string, routeEntry *RouteEntry) (err error) {
+	buf, err := yaml.Marshal(routeEntry)
+	if nil != err {
+		return
+	}
+	return ioutil.WriteFile(configFilePath, buf, 0644)
+}
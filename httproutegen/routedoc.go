@@ -0,0 +1,133 @@
+package httproutegen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func describeByte(b byte) string {
+	switch {
+	case b == ' ':
+		return "space"
+	case b == '`':
+		return "`` ` ``"
+	case (b < 0x20) || (b > 0x7E):
+		return fmt.Sprintf("`0x%02X`", b)
+	}
+	return "`" + string(b) + "`"
+}
+
+func describeByteRanges(ranges []ByteRange) string {
+	var parts []string
+	for _, r := range ranges {
+		switch {
+		case r.From == r.To:
+			parts = append(parts, describeByte(r.From))
+		case r.To == r.From+1:
+			parts = append(parts, describeByte(r.From), describeByte(r.To))
+		default:
+			parts = append(parts, describeByte(r.From)+" to "+describeByte(r.To))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DescribeByteMap return enabled bytes of given mapper in human readable form.
+func DescribeByteMap(m *ByteMapper) string {
+	var printables, excluded ByteMapper
+	printables.enablePrintables()
+	excluded.enablePrintables()
+	enabledCount := 0
+	printableOnly := true
+	for b := 0; b < 128; b++ {
+		if !m.HasByte(byte(b)) {
+			continue
+		}
+		enabledCount++
+		if !printables.HasByte(byte(b)) {
+			printableOnly = false
+		}
+		excluded.disableByte(byte(b))
+	}
+	excludedRanges := excluded.ByteRanges()
+	if printableOnly && (enabledCount > 0) && (len(excludedRanges) < len(m.ByteRanges())) {
+		if len(excludedRanges) == 0 {
+			return "printable ASCII"
+		}
+		return "printable ASCII except " + describeByteRanges(excludedRanges)
+	}
+	return describeByteRanges(m.ByteRanges())
+}
+
+func describeMatchingMode(target *RouteTarget) (result []string) {
+	for _, routeEntry := range append(target.Ancestors, target.Route) {
+		componentIdent := "/" + strings.Trim(routeEntry.Ident, "/")
+		if routeEntry.StrictMatch {
+			result = append(result, "strict match of `"+componentIdent+"`")
+		} else if "" != routeEntry.StrictPrefixMatch {
+			result = append(result, "strict prefix `"+routeEntry.StrictPrefixMatch+"` at `"+componentIdent+"`")
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, "discriminating bytes only")
+	}
+	return
+}
+
+func writeRouteTargetDocument(w io.Writer, target *RouteTarget) (err error) {
+	var methodNames []string
+	for _, invokeProfile := range target.Route.HandlerProfile.InvokeProfiles {
+		methodNames = append(methodNames, invokeProfile.RequestMethod)
+	}
+	text := "### `" + strings.Join(methodNames, ", ") + "` `" + target.Template + "`\n\n" +
+		"* Pattern: `` " + target.Pattern + " ``\n" +
+		"* Handlers:\n"
+	for _, handlerName := range target.HandlerNames() {
+		text += "  - `" + handlerName + "`: " + strings.Join(target.HandlerMethods(handlerName), ", ") + "\n"
+	}
+	text += "* Matching: " + strings.Join(describeMatchingMode(target), "; ") + "\n"
+	if target.Route.TrailingSlash {
+		text += "* Trailing slash: enabled\n"
+	}
+	if len(target.Parameters) > 0 {
+		text += "\n| Parameter | Type | Allowed Bytes |\n" +
+			"| --- | --- | --- |\n"
+		for _, param := range target.Parameters {
+			text += "| `" + param.Name + "` | `" + param.Type + "` | " + DescribeByteMap(&param.Sequence.ByteMap) + " |\n"
+		}
+	}
+	_, err = io.WriteString(w, text+"\n")
+	return
+}
+
+// WriteRouteDocument write Markdown reference document of route targets grouped by area.
+func WriteRouteDocument(w io.Writer, title string, targets []*RouteTarget) (err error) {
+	var areaNames []string
+	areaTargets := make(map[string][]*RouteTarget)
+	for _, target := range targets {
+		areaName := target.Route.AreaName
+		if _, ok := areaTargets[areaName]; !ok {
+			areaNames = append(areaNames, areaName)
+		}
+		areaTargets[areaName] = append(areaTargets[areaName], target)
+	}
+	if _, err = io.WriteString(w, "# "+title+"\n\n"); nil != err {
+		return
+	}
+	for _, areaName := range areaNames {
+		heading := "## Area: " + areaName
+		if "" == areaName {
+			heading = "## Default Area"
+		}
+		if _, err = io.WriteString(w, heading+"\n\n"); nil != err {
+			return
+		}
+		for _, target := range areaTargets[areaName] {
+			if err = writeRouteTargetDocument(w, target); nil != err {
+				return
+			}
+		}
+	}
+	return nil
+}
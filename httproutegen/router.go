@@ -0,0 +1,237 @@
+package httproutegen
+
+import (
+	"fmt"
+)
+
+// RouteParameterValue is a path parameter extracted by Router.
+type RouteParameterValue struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// RouteResult is the outcome of routing a request path with Router.
+// HandlerName and Parameters are only available when a handler is matched.
+type RouteResult struct {
+	RouteIdent  string                 `json:"route_ident"`
+	HandlerName string                 `json:"handler_name,omitempty"`
+	Parameters  []*RouteParameterValue `json:"parameters,omitempty"`
+	PathOffset  int                    `json:"path_offset"`
+}
+
+// Router evaluate expanded fanout forks against request paths without generating code.
+// The result is the same as the route method generated from the same fanout instance.
+type Router struct {
+	NamePrefix string
+
+	symbolScope    *SymbolScope
+	rootFanoutFork *FanoutFork
+}
+
+// NewRouter create router from expanded fanout instance.
+func NewRouter(fanoutInstance *FanoutInstance) *Router {
+	return &Router{
+		symbolScope:    &fanoutInstance.InstanceSymbolScope,
+		rootFanoutFork: fanoutInstance.RootFanoutFork,
+	}
+}
+
+type routerState struct {
+	method  string
+	reqPath string
+	offset  int
+	bound   int
+	params  []*RouteParameterValue
+}
+
+func (r *Router) makeIdentResult(state *routerState, identName string) *RouteResult {
+	return &RouteResult{
+		RouteIdent: identName,
+		PathOffset: state.offset,
+	}
+}
+
+// Route evaluate given request method and path.
+// Error from parameter extraction or prefix matching is returned with RouteError or RouteMiss result.
+func (r *Router) Route(method, reqPath string) (result *RouteResult, err error) {
+	state := &routerState{
+		method:  method,
+		reqPath: reqPath,
+		bound:   len(reqPath),
+	}
+	for state.offset < state.bound {
+		if reqPath[state.offset] == '/' {
+			state.offset++
+			break
+		}
+		state.offset++
+	}
+	if state.offset >= state.bound {
+		return r.makeIdentResult(state, r.NamePrefix+"RouteNone"), nil
+	}
+	if nil != r.rootFanoutFork {
+		var done bool
+		if result, done, err = r.evaluateFork(state, r.rootFanoutFork); done {
+			return
+		}
+	}
+	return r.makeIdentResult(state, r.NamePrefix+"RouteNone"), nil
+}
+
+func (r *Router) evaluateSubForks(state *routerState, fanoutFork *FanoutFork, terminateSerials []int32) (result *RouteResult, done bool, err error) {
+	for _, subFork := range fanoutFork.FindChildForkViaTerminateSerials(terminateSerials) {
+		if result, done, err = r.evaluateFork(state, subFork); done {
+			return
+		}
+	}
+	return nil, false, nil
+}
+
+func (r *Router) evaluatePrefixMatching(state *routerState, fanoutFork *FanoutFork) (result *RouteResult, done bool, err error) {
+	routeMissingIdentName := makeRouteMissingIdentName(r.NamePrefix, fanoutFork.AreaName)
+	offset := state.offset + fanoutFork.BaseOffset
+	b := offset + fanoutFork.PrefixLiteralDigests.Depth
+	fallbackDigests := fanoutFork.PrefixFallbackDigests
+	if (nil != fallbackDigests) && (b > state.bound) {
+		b = offset + fallbackDigests.Depth
+	} else {
+		fallbackDigests = nil
+	}
+	if b > state.bound {
+		state.offset = offset
+		return r.makeIdentResult(state, pickNonEmptyIdent(routeMissingIdentName, r.NamePrefix+"RouteError")), true, ErrFragmentSmallerThanExpect
+	}
+	var digest uint64
+	for ; offset < b; offset++ {
+		digest = (digest << 8) | uint64(state.reqPath[offset])
+	}
+	state.offset = offset
+	if nil != fallbackDigests {
+		// Remaining path is shorter than merged digest, only the first step is compared.
+		for _, digestSet := range fallbackDigests.Digests {
+			if digestSet.Value == digest {
+				return r.makeIdentResult(state, pickNonEmptyIdent(routeMissingIdentName, r.NamePrefix+"RouteError")), true, ErrFragmentSmallerThanExpect
+			}
+		}
+	} else {
+		for _, digestSet := range fanoutFork.PrefixLiteralDigests.Digests {
+			if digestSet.Value != digest {
+				continue
+			}
+			if result, done, err = r.evaluateSubForks(state, fanoutFork, digestSet.TerminateSerials); done {
+				return
+			}
+			break
+		}
+	}
+	if routeMissingIdentName != "" && fanoutFork.IsTipAreaFork() {
+		return r.makeIdentResult(state, routeMissingIdentName), true, nil
+	}
+	return nil, false, nil
+}
+
+func (r *Router) evaluateFuzzyMatching(state *routerState, fanoutFork *FanoutFork) (result *RouteResult, done bool, err error) {
+	var bestDepth int
+	var trackSets []*FanoutFuzzyTrackSet
+	switch fanoutFork.FuzzyModeBit {
+	case 8:
+		bestDepth, trackSets = fanoutFork.FuzzyTracker.BestU8Depth, fanoutFork.FuzzyTracker.BestU8
+	case 16:
+		bestDepth, trackSets = fanoutFork.FuzzyTracker.BestU16Depth, fanoutFork.FuzzyTracker.BestU16
+	default:
+		return nil, false, nil
+	}
+	routeMissingIdentName := makeRouteMissingIdentName(r.NamePrefix, fanoutFork.AreaName)
+	state.offset += fanoutFork.BaseOffset + bestDepth
+	if state.offset >= state.bound {
+		return r.makeIdentResult(state, pickNonEmptyIdent(routeMissingIdentName, r.NamePrefix+"RouteIncomplete")), true, nil
+	}
+	ch := uint32(state.reqPath[state.offset])
+	if fanoutFork.FuzzyModeBit == 16 {
+		ch = (uint32(state.reqPath[state.offset-1]) << 8) | ch
+	}
+	for _, trackSet := range trackSets {
+		if trackSet.Value != ch {
+			continue
+		}
+		if result, done, err = r.evaluateSubForks(state, fanoutFork, trackSet.TerminateSerials); done {
+			return
+		}
+		break
+	}
+	if fanoutFork.IsTipAreaFork() {
+		return r.makeIdentResult(state, routeMissingIdentName), true, nil
+	}
+	return nil, false, nil
+}
+
+func (r *Router) evaluateGetParameter(state *routerState, fanoutFork *FanoutFork) (result *RouteResult, done bool, err error) {
+	seqPart := r.symbolScope.FoundSequences[fanoutFork.SequenceIndex]
+	routeMissingIdentName := makeRouteMissingIdentName(r.NamePrefix, fanoutFork.AreaName)
+	value, offset, err := extractSequenceValue(seqPart, state.reqPath, state.offset+fanoutFork.BaseOffset, state.bound)
+	state.offset = offset
+	if nil != err {
+		return r.makeIdentResult(state, pickNonEmptyIdent(routeMissingIdentName, r.NamePrefix+"RouteError")), true, err
+	}
+	paramCount := len(state.params)
+	state.params = append(state.params, &RouteParameterValue{
+		Name:  fanoutFork.SequenceVarName,
+		Type:  seqPart.VariableType,
+		Value: value,
+	})
+	if result, done, err = r.evaluateSubForks(state, fanoutFork, fanoutFork.CoveredTerminals); done {
+		return
+	}
+	state.params = state.params[:paramCount]
+	return nil, false, nil
+}
+
+func (r *Router) lookupParameter(state *routerState, paramName string) *RouteParameterValue {
+	for idx := len(state.params) - 1; idx >= 0; idx-- {
+		if state.params[idx].Name == paramName {
+			return state.params[idx]
+		}
+	}
+	return nil
+}
+
+func (r *Router) evaluateInvokeHandler(state *routerState, fanoutFork *FanoutFork) (result *RouteResult, done bool, err error) {
+	invokeProfiles := fanoutFork.InvokeHandlerFanout.Route.HandlerProfile.InvokeProfiles
+	matched := false
+	for _, invokeProfile := range invokeProfiles {
+		if !matched && (invokeProfile.RequestMethod != state.method) {
+			continue
+		}
+		matched = true
+		if invokeProfile.SameNext {
+			continue
+		}
+		result = &RouteResult{
+			RouteIdent:  makeRouteTargetIdentName(r.NamePrefix, invokeProfile.HandlerName),
+			HandlerName: invokeProfile.HandlerName,
+			PathOffset:  state.offset + fanoutFork.BaseOffset,
+		}
+		for _, paramName := range fanoutFork.AvailableSequenceVarName {
+			if param := r.lookupParameter(state, paramName); nil != param {
+				result.Parameters = append(result.Parameters, param)
+			}
+		}
+		return result, true, nil
+	}
+	return r.makeIdentResult(state, r.NamePrefix+"RouteMethodNotAllowed"), true, nil
+}
+
+func (r *Router) evaluateFork(state *routerState, fanoutFork *FanoutFork) (result *RouteResult, done bool, err error) {
+	switch fanoutFork.LogicType {
+	case LogicTypePrefixMatching:
+		return r.evaluatePrefixMatching(state, fanoutFork)
+	case LogicTypeFuzzyMatching:
+		return r.evaluateFuzzyMatching(state, fanoutFork)
+	case LogicTypeGetParameter:
+		return r.evaluateGetParameter(state, fanoutFork)
+	case LogicTypeInvokeHandler:
+		return r.evaluateInvokeHandler(state, fanoutFork)
+	}
+	return nil, true, fmt.Errorf("unknown logic type: %v (%v)", fanoutFork.LogicType, fanoutFork.CoveredTerminals)
+}
@@ -0,0 +1,103 @@
+package httproutegen
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const testSampleRouteConfigPath = "../sample/route.yaml"
+
+func loadTestSampleFanoutInstance(t *testing.T) (rootRouteEntry *RouteEntry, fanoutInstance *FanoutInstance) {
+	rootRouteEntry, err := LoadYAML(testSampleRouteConfigPath)
+	if nil != err {
+		t.Fatalf("cannot load route configuration: %v", err)
+	}
+	if fanoutInstance, err = MakeFanoutInstance(rootRouteEntry); nil != err {
+		t.Fatalf("cannot create fanout instance: %v", err)
+	}
+	if err = fanoutInstance.ExpandFanout(); nil != err {
+		t.Fatalf("cannot expand fanout instance: %v", err)
+	}
+	return
+}
+
+func TestRouterRoute(t *testing.T) {
+	_, fanoutInstance := loadTestSampleFanoutInstance(t)
+	router := NewRouter(fanoutInstance)
+	testCases := []struct {
+		method      string
+		path        string
+		routeIdent  string
+		handlerName string
+		params      []interface{}
+		pathOffset  int
+		failed      bool
+	}{
+		{http.MethodGet, "", "RouteNone", "", nil, 0, false},
+		{http.MethodGet, "/", "RouteNone", "", nil, 1, false},
+		{http.MethodGet, "/nothing", "RouteNone", "", nil, 5, false},
+		{http.MethodGet, "/sample-api/query/widget", "RouteToQueryProduct", "queryProduct", []interface{}{"widget"}, 24, false},
+		{http.MethodPost, "/sample-api/query/widget", "RouteToQueryProduct", "queryProduct", []interface{}{"widget"}, 24, false},
+		{http.MethodDelete, "/sample-api/query/widget", "RouteMethodNotAllowed", "", nil, 24, false},
+		{http.MethodGet, "/sample-api/query/wid%get", "RouteToQueryProduct", "queryProduct", []interface{}{"wid"}, 21, false},
+		{http.MethodGet, "/sample-api/download/12/34", "RouteToDownloadProduct", "downloadProduct", []interface{}{int64(12), int64(34)}, 26, false},
+		{http.MethodPost, "/sample-api/download/12/34", "RouteMethodNotAllowed", "", nil, 26, false},
+		{http.MethodGet, "/sample-api/download/12/", "RouteError", "", nil, 24, true},
+		{http.MethodGet, "/sample-admin-api/products", "RouteToListProducts", "listProducts", nil, 26, false},
+		{http.MethodGet, "/sample-admin-api/product/9", "RouteToShowProduct", "showProduct", []interface{}{int64(9)}, 27, false},
+		{http.MethodGet, "/sample-data", "RouteToSampleData", "sampleData", nil, 12, false},
+		{http.MethodGet, "/sample-data/x", "RouteToSampleData", "sampleData", nil, 12, false},
+		{http.MethodGet, "/sample-datx", "RouteNone", "", nil, 12, false},
+		{http.MethodGet, "/debug-sample/text/{7}/fF/10", "RouteToDebugNumber", "debugNumber", []interface{}{int32(7), int32(255), uint32(16)}, 28, false},
+		{http.MethodGet, "/unique-path/json/3", "RouteToUniqueJSON", "uniqueJSON", []interface{}{int32(3)}, 19, false},
+	}
+	for _, tc := range testCases {
+		result, err := router.Route(tc.method, tc.path)
+		if (nil != err) != tc.failed {
+			t.Errorf("%s %q: unexpected error: %v", tc.method, tc.path, err)
+			continue
+		}
+		if nil == result {
+			t.Errorf("%s %q: result is nil", tc.method, tc.path)
+			continue
+		}
+		if (result.RouteIdent != tc.routeIdent) || (result.HandlerName != tc.handlerName) || (result.PathOffset != tc.pathOffset) {
+			t.Errorf("%s %q: expect (%s, %q, %d) but have (%s, %q, %d)", tc.method, tc.path,
+				tc.routeIdent, tc.handlerName, tc.pathOffset, result.RouteIdent, result.HandlerName, result.PathOffset)
+			continue
+		}
+		var params []interface{}
+		for _, param := range result.Parameters {
+			params = append(params, param.Value)
+		}
+		if !reflect.DeepEqual(params, tc.params) {
+			t.Errorf("%s %q: expect parameters %#v but have %#v", tc.method, tc.path, tc.params, params)
+		}
+	}
+}
+
+func TestRouterNamePrefix(t *testing.T) {
+	_, fanoutInstance := loadTestSampleFanoutInstance(t)
+	router := NewRouter(fanoutInstance)
+	router.NamePrefix = "Sample"
+	testCases := []struct {
+		method     string
+		path       string
+		routeIdent string
+	}{
+		{http.MethodGet, "/nothing", "SampleRouteNone"},
+		{http.MethodGet, "/sample-data", "SampleRouteToSampleData"},
+		{http.MethodPost, "/sample-data", "SampleRouteMethodNotAllowed"},
+	}
+	for _, tc := range testCases {
+		result, err := router.Route(tc.method, tc.path)
+		if nil != err {
+			t.Errorf("%s %q: unexpected error: %v", tc.method, tc.path, err)
+			continue
+		}
+		if result.RouteIdent != tc.routeIdent {
+			t.Errorf("%s %q: expect %s but have %s", tc.method, tc.path, tc.routeIdent, result.RouteIdent)
+		}
+	}
+}
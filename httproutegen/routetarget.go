@@ -0,0 +1,131 @@
+package httproutegen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RouteParameter represent one path parameter of route target.
+type RouteParameter struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Sequence *SequencePart `json:"sequence"`
+}
+
+// RouteTarget represent a terminate route entry with resolved path information.
+// Ancestors and YAMLPath are only available for targets from CollectRouteTargets.
+// Template is the path with parameters as {name}, literal braces and percent
+// signs in it are percent-encoded.
+type RouteTarget struct {
+	Route      *RouteEntry       `json:"route"`
+	Ancestors  []*RouteEntry     `json:"-"`
+	YAMLPath   string            `json:"yaml_path,omitempty"`
+	Pattern    string            `json:"pattern"`
+	Template   string            `json:"template"`
+	Symbols    []Symbol          `json:"symbols,omitempty"`
+	Parameters []*RouteParameter `json:"parameters,omitempty"`
+}
+
+// appendTemplateLiteralByte append literal byte of path into template.
+// Braces are percent-encoded so they cannot be taken as parameter
+// placeholders, and so is percent sign to keep the encoding reversible.
+func appendTemplateLiteralByte(templateBuf []byte, ch byte) []byte {
+	switch ch {
+	case '{':
+		return append(templateBuf, "%7B"...)
+	case '}':
+		return append(templateBuf, "%7D"...)
+	case '%':
+		return append(templateBuf, "%25"...)
+	}
+	return append(templateBuf, ch)
+}
+
+// MakeRouteTarget create route target from verified terminate route entry.
+func MakeRouteTarget(symbolScope *SymbolScope, routeEntry *RouteEntry) (target *RouteTarget, err error) {
+	pattern := strings.TrimSuffix(routeEntry.Ident, "/")
+	symbols, err := symbolScope.parseComponentOf(routeEntry.Ident, []byte(strings.TrimPrefix(pattern, "/")))
+	if nil != err {
+		err = newErrParseComponent(routeEntry.Ident, err)
+		return
+	}
+	target = &RouteTarget{
+		Route:   routeEntry,
+		Pattern: "/" + strings.TrimPrefix(pattern, "/"),
+		Symbols: symbols,
+	}
+	templateBuf := []byte{'/'}
+	for _, sym := range symbols {
+		switch sym.Type {
+		case SymbolTypeByte:
+			templateBuf = appendTemplateLiteralByte(templateBuf, sym.ByteValue)
+		case SymbolTypeSequence:
+			templateBuf = append(templateBuf, '{')
+			templateBuf = append(templateBuf, sym.SequenceVarName...)
+			templateBuf = append(templateBuf, '}')
+			target.Parameters = append(target.Parameters, &RouteParameter{
+				Name:     sym.SequenceVarName,
+				Type:     sym.SequenceValue.VariableType,
+				Sequence: sym.SequenceValue,
+			})
+		}
+	}
+	target.Template = string(templateBuf)
+	return
+}
+
+// HandlerMethods return request methods routed to given handler name.
+func (target *RouteTarget) HandlerMethods(handlerName string) (result []string) {
+	for _, invokeProfile := range target.Route.HandlerProfile.InvokeProfiles {
+		if invokeProfile.HandlerName == handlerName {
+			result = append(result, invokeProfile.RequestMethod)
+		}
+	}
+	return
+}
+
+// HandlerNames return distinct handler names of this target in evaluate order.
+func (target *RouteTarget) HandlerNames() (result []string) {
+	for _, invokeProfile := range target.Route.HandlerProfile.InvokeProfiles {
+		found := false
+		for _, n := range result {
+			if n == invokeProfile.HandlerName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, invokeProfile.HandlerName)
+		}
+	}
+	return
+}
+
+func collectRouteTargets(symbolScope *SymbolScope, routeEntry *RouteEntry, ancestors []*RouteEntry, yamlPath string, result []*RouteTarget) ([]*RouteTarget, error) {
+	if len(routeEntry.Routes) == 0 {
+		target, err := MakeRouteTarget(symbolScope, routeEntry)
+		if nil != err {
+			return nil, err
+		}
+		target.Ancestors = append(target.Ancestors, ancestors...)
+		target.YAMLPath = yamlPath
+		return append(result, target), nil
+	}
+	ancestors = append(ancestors[:len(ancestors):len(ancestors)], routeEntry)
+	if "" != yamlPath {
+		yamlPath += "."
+	}
+	for idx, childEntry := range routeEntry.Routes {
+		var err error
+		if result, err = collectRouteTargets(symbolScope, childEntry, ancestors, yamlPath+"route["+strconv.Itoa(idx)+"]", result); nil != err {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// CollectRouteTargets get route targets from verified root route entry in configuration order.
+func CollectRouteTargets(rootRouteEntry *RouteEntry) (targets []*RouteTarget, err error) {
+	var symbolScope SymbolScope
+	return collectRouteTargets(&symbolScope, rootRouteEntry, nil, "", nil)
+}
@@ -0,0 +1,259 @@
+package httproutegen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RouteSamplePath is a request path synthesized from symbols of route target.
+type RouteSamplePath struct {
+	Target     *RouteTarget           `json:"-"`
+	Path       string                 `json:"path"`
+	Parameters []*RouteParameterValue `json:"parameters,omitempty"`
+}
+
+const preferredSampleText = "Sample-01"
+
+func isHexByteMap(m *ByteMapper) bool {
+	for _, r := range m.ByteRanges() {
+		switch {
+		case (r.From >= '0') && (r.To <= '9'):
+		case (r.From >= 'A') && (r.To <= 'F'):
+		case (r.From >= 'a') && (r.To <= 'f'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isSampleByte check if given byte can be placed in synthesized path without escaping.
+func isSampleByte(m *ByteMapper, b byte, stopByte int) bool {
+	if (b <= 0x20) || (b >= 0x7F) || (int(b) == stopByte) {
+		return false
+	}
+	switch b {
+	case '/', '?', '#', '%':
+		return false
+	}
+	return m.HasByte(b)
+}
+
+func filterSampleText(m *ByteMapper, text string, stopByte int) string {
+	var b []byte
+	for _, ch := range []byte(text) {
+		if isSampleByte(m, ch, stopByte) {
+			b = append(b, ch)
+		}
+	}
+	return string(b)
+}
+
+// makeSampleSequenceTexts return candidate texts for given sequence.
+// The stopByte is the literal byte following the sequence or -1 if there is none.
+func makeSampleSequenceTexts(seqPart *SequencePart, stopByte int) (result []string) {
+	m := &seqPart.ByteMap
+	switch seqPart.VariableType {
+	case "int32", "uint32", "int64", "uint64":
+		if !isDecimalByteMap(m) && isHexByteMap(m) {
+			for _, text := range []string{"2f", "2F"} {
+				if filterSampleText(m, text, stopByte) == text {
+					return append(result, text)
+				}
+			}
+		}
+		if text := filterSampleText(m, "42", stopByte); len(text) == 2 {
+			result = append(result, text)
+			if strings.HasPrefix(seqPart.VariableType, "int") && isSampleByte(m, '-', stopByte) && isDecimalByteMap(m) {
+				result = append(result, "-7")
+			}
+			return
+		}
+	}
+	if text := filterSampleText(m, preferredSampleText, stopByte); "" != text {
+		return append(result, text)
+	}
+	var b []byte
+	for ch := 0x21; (ch < 0x7F) && (len(b) < 4); ch++ {
+		if isSampleByte(m, byte(ch), stopByte) {
+			b = append(b, byte(ch))
+		}
+	}
+	if len(b) > 0 {
+		result = append(result, string(b))
+	}
+	return
+}
+
+// makeReferenceParameterValue convert text of sequence into value by the byte map and type of sequence.
+// Integer sequences of hexadecimal digits are parsed as base 16 numbers.
+func makeReferenceParameterValue(seqPart *SequencePart, text string) (value interface{}, ok bool) {
+	if "" != seqPart.Converter {
+		return nil, false
+	}
+	base := 10
+	if !isDecimalByteMap(&seqPart.ByteMap) {
+		if !isHexByteMap(&seqPart.ByteMap) {
+			base = 0
+		} else {
+			base = 16
+		}
+	}
+	switch seqPart.VariableType {
+	case "string":
+		return text, true
+	case "[]byte":
+		return []byte(text), true
+	case "int32", "int64":
+		if 0 == base {
+			return nil, false
+		}
+		v, err := strconv.ParseInt(text, base, 64)
+		if nil != err {
+			return nil, false
+		}
+		if seqPart.VariableType == "int32" {
+			if int64(int32(v)) != v {
+				return nil, false
+			}
+			return int32(v), true
+		}
+		return v, true
+	case "uint32", "uint64":
+		if 0 == base {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(text, base, 64)
+		if nil != err {
+			return nil, false
+		}
+		if seqPart.VariableType == "uint32" {
+			if uint64(uint32(v)) != v {
+				return nil, false
+			}
+			return uint32(v), true
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+func (target *RouteTarget) makeSamplePath(seqTexts [][]string, alternative bool) (samplePath *RouteSamplePath, ok bool) {
+	b := []byte{'/'}
+	samplePath = &RouteSamplePath{
+		Target: target,
+	}
+	seqIndex := 0
+	for _, sym := range target.Symbols {
+		switch sym.Type {
+		case SymbolTypeByte:
+			b = append(b, sym.ByteValue)
+		case SymbolTypeSequence:
+			candidates := seqTexts[seqIndex]
+			seqIndex++
+			text := candidates[0]
+			if alternative {
+				text = candidates[len(candidates)-1]
+			}
+			value, ok := makeReferenceParameterValue(sym.SequenceValue, text)
+			if !ok {
+				return nil, false
+			}
+			b = append(b, text...)
+			samplePath.Parameters = append(samplePath.Parameters, &RouteParameterValue{
+				Name:  sym.SequenceVarName,
+				Type:  sym.SequenceValue.VariableType,
+				Value: value,
+			})
+		}
+	}
+	samplePath.Path = string(b)
+	return samplePath, true
+}
+
+// MakeSamplePaths synthesize request paths which fully match this target.
+// Empty result is returned if no valid sample value can be made for any sequence.
+func (target *RouteTarget) MakeSamplePaths() (result []*RouteSamplePath) {
+	var seqTexts [][]string
+	hasAlternative := false
+	for idx, sym := range target.Symbols {
+		if sym.Type != SymbolTypeSequence {
+			continue
+		}
+		stopByte := -1
+		if (idx+1 < len(target.Symbols)) && (target.Symbols[idx+1].Type == SymbolTypeByte) {
+			stopByte = int(target.Symbols[idx+1].ByteValue)
+		}
+		candidates := makeSampleSequenceTexts(sym.SequenceValue, stopByte)
+		if len(candidates) == 0 {
+			return nil
+		}
+		if len(candidates) > 1 {
+			hasAlternative = true
+		}
+		seqTexts = append(seqTexts, candidates)
+	}
+	if samplePath, ok := target.makeSamplePath(seqTexts, false); ok {
+		result = append(result, samplePath)
+	}
+	if hasAlternative {
+		if samplePath, ok := target.makeSamplePath(seqTexts, true); ok {
+			result = append(result, samplePath)
+		}
+	}
+	return
+}
+
+// MatchPath check if given request path fully match pattern of this target.
+// Sequences are matched greedily with bytes allowed by byte map and must not be empty.
+// Paths with parameter text which cannot be converted into value are treated as not matched.
+func (target *RouteTarget) MatchPath(reqPath string) (params []*RouteParameterValue, ok bool) {
+	offset := strings.IndexByte(reqPath, '/') + 1
+	if offset == 0 {
+		return nil, false
+	}
+	bound := len(reqPath)
+	for _, sym := range target.Symbols {
+		switch sym.Type {
+		case SymbolTypeByte:
+			if (offset >= bound) || (reqPath[offset] != sym.ByteValue) {
+				return nil, false
+			}
+			offset++
+		case SymbolTypeSequence:
+			idx := offset
+			for (idx < bound) && sym.SequenceValue.ByteMap.HasByte(reqPath[idx]) {
+				idx++
+			}
+			if idx == offset {
+				return nil, false
+			}
+			value, ok := makeReferenceParameterValue(sym.SequenceValue, reqPath[offset:idx])
+			if !ok {
+				return nil, false
+			}
+			params = append(params, &RouteParameterValue{
+				Name:  sym.SequenceVarName,
+				Type:  sym.SequenceValue.VariableType,
+				Value: value,
+			})
+			offset = idx
+		}
+	}
+	if offset != bound {
+		return nil, false
+	}
+	return params, true
+}
+
+// HandlerNameOfMethod return name of handler for given request method or empty string if method is not routed.
+func (target *RouteTarget) HandlerNameOfMethod(methodName string) string {
+	for _, handlerName := range target.HandlerNames() {
+		for _, m := range target.HandlerMethods(handlerName) {
+			if m == methodName {
+				return handlerName
+			}
+		}
+	}
+	return ""
+}
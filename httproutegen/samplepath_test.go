@@ -0,0 +1,111 @@
+package httproutegen
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func findTestRouteTarget(t *testing.T, targets []*RouteTarget, handlerName string) *RouteTarget {
+	for _, target := range targets {
+		if target.HandlerNameOfMethod(http.MethodGet) == handlerName {
+			return target
+		}
+	}
+	t.Fatalf("cannot find route target of handler %s", handlerName)
+	return nil
+}
+
+func TestMakeSamplePaths(t *testing.T) {
+	rootRouteEntry, fanoutInstance := loadTestSampleFanoutInstance(t)
+	targets, err := CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		t.Fatalf("cannot collect route targets: %v", err)
+	}
+	testCases := []struct {
+		handlerName string
+		path        string
+		params      []interface{}
+	}{
+		{"queryProduct", "/sample-api/query/Sample-01", []interface{}{"Sample-01"}},
+		{"downloadProduct", "/sample-api/download/42/42", []interface{}{int64(42), int64(42)}},
+		{"listProducts", "/sample-admin-api/products", nil},
+		{"sampleData", "/sample-data", nil},
+		{"debugNumber", "/debug-sample/text/{42}/2f/2f", []interface{}{int32(42), int32(47), uint32(47)}},
+	}
+	router := NewRouter(fanoutInstance)
+	for _, tc := range testCases {
+		target := findTestRouteTarget(t, targets, tc.handlerName)
+		samplePaths := target.MakeSamplePaths()
+		if len(samplePaths) == 0 {
+			t.Errorf("%s: no sample path", tc.handlerName)
+			continue
+		}
+		samplePath := samplePaths[0]
+		var params []interface{}
+		for _, param := range samplePath.Parameters {
+			params = append(params, param.Value)
+		}
+		if (samplePath.Path != tc.path) || !reflect.DeepEqual(params, tc.params) {
+			t.Errorf("%s: expect sample path %q %#v but have %q %#v", tc.handlerName, tc.path, tc.params, samplePath.Path, params)
+			continue
+		}
+		for _, samplePath := range samplePaths {
+			if _, ok := target.MatchPath(samplePath.Path); !ok {
+				t.Errorf("%s: sample path %q does not match its own target", tc.handlerName, samplePath.Path)
+			}
+			if result, err := router.Route(http.MethodGet, samplePath.Path); nil != err {
+				t.Errorf("%s: cannot route sample path %q: %v", tc.handlerName, samplePath.Path, err)
+			} else if result.HandlerName != tc.handlerName {
+				t.Errorf("%s: sample path %q is routed to %q", tc.handlerName, samplePath.Path, result.HandlerName)
+			}
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	rootRouteEntry := loadTestRouteConfig(t, `route:
+- c: 'item/{0-9, id int32}/{a-z, name string}'
+  handler:
+    get: "showItem"
+- c: 'hex/{0-9a-f, code uint32}'
+  handler:
+    get: "showHex"
+`)
+	targets, err := CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		t.Fatalf("cannot collect route targets: %v", err)
+	}
+	testCases := []struct {
+		handlerName string
+		path        string
+		ok          bool
+		params      []interface{}
+	}{
+		{"showItem", "/item/12/abc", true, []interface{}{int32(12), "abc"}},
+		{"showItem", "item/12/abc", false, nil},
+		{"showItem", "/item/12/abc/", false, nil},
+		{"showItem", "/item//abc", false, nil},
+		{"showItem", "/item/12/", false, nil},
+		{"showItem", "/item/12/ABC", false, nil},
+		{"showItem", "/item/99999999999/abc", false, nil},
+		{"showItem", "/items/12/abc", false, nil},
+		{"showHex", "/hex/ff", true, []interface{}{uint32(255)}},
+		{"showHex", "/hex/fg", false, nil},
+	}
+	for _, tc := range testCases {
+		target := findTestRouteTarget(t, targets, tc.handlerName)
+		paramValues, ok := target.MatchPath(tc.path)
+		if ok != tc.ok {
+			t.Errorf("%s %q: expect match result %v but have %v", tc.handlerName, tc.path, tc.ok, ok)
+			continue
+		}
+		var params []interface{}
+		for _, param := range paramValues {
+			params = append(params, param.Value)
+		}
+		if !reflect.DeepEqual(params, tc.params) {
+			t.Errorf("%s %q: expect parameters %#v but have %#v", tc.handlerName, tc.path, tc.params, params)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package httproutegen
 
 import (
 	"errors"
-	"log"
 )
 
 const boundaryOfSequenceNumber = 0x9D
@@ -29,6 +28,7 @@ type Symbol struct {
 }
 
 // ByteCode is code to represent the symbol in sequence.
+// Sequence with index out of range is reported on parsing and get 0xFF.
 func (sym *Symbol) ByteCode() byte {
 	switch sym.Type {
 	case SymbolTypeNoop:
@@ -37,7 +37,6 @@ func (sym *Symbol) ByteCode() byte {
 		return sym.ByteValue - ' '
 	case SymbolTypeSequence:
 		if sym.SequenceIndex > boundaryOfSequenceNumber {
-			log.Printf("ERROR: sequence index > 0x%02X: %#v", boundaryOfSequenceNumber, sym)
 			return 0xFF
 		}
 		return 0x60 + byte(sym.SequenceIndex)
@@ -69,6 +68,8 @@ func newSequenceSymbol(value *SequencePart, index int, variableName string) Symb
 // SymbolScope represent one shared space of symbol parsing operation.
 type SymbolScope struct {
 	FoundSequences []*SequencePart `json:"found_sequences"`
+
+	diagnostics Diagnostics
 }
 
 func (scope *SymbolScope) attachSequencePart(seqPart *SequencePart) (int, *SequencePart) {
@@ -84,16 +85,33 @@ func (scope *SymbolScope) attachSequencePart(seqPart *SequencePart) (int, *Seque
 
 // ParseComponent parse given bytes as component.
 func (scope *SymbolScope) ParseComponent(c []byte) (result []Symbol, err error) {
+	return scope.parseComponentOf("", c)
+}
+
+// parseComponentOf parse given bytes as component of route with given ident.
+// The route ident is attached to reported diagnostics.
+func (scope *SymbolScope) parseComponentOf(routeIdent string, c []byte) (result []Symbol, err error) {
 	for len(c) > 0 {
 		if ch := c[0]; ch == '{' {
 			seqPart := &SequencePart{}
 			nextIdx, err := seqPart.setSeqence(c)
 			if nil != err {
-				log.Printf("ERROR: failed on set sequence to part: %v", string(c))
 				return nil, err
 			}
+			for _, b := range c[:nextIdx] {
+				if b > 127 {
+					reportDiagnostic(scope.diagnostics, DiagnosticWarning, DiagnosticByteOutOfRange, routeIdent,
+						"byte > 127 in sequence is ignored: %s", string(c[:nextIdx]))
+					break
+				}
+			}
 			varName := seqPart.VariableName
 			seqIndex, seqPart := scope.attachSequencePart(seqPart)
+			if seqIndex > boundaryOfSequenceNumber {
+				reportDiagnostic(scope.diagnostics, DiagnosticError, DiagnosticSequenceIndexOutOfRange, routeIdent,
+					"sequence index %d > 0x%02X, byte code of sequence is not available: %s",
+					seqIndex, boundaryOfSequenceNumber, string(c[:nextIdx]))
+			}
 			result = append(result, newSequenceSymbol(seqPart, seqIndex, varName))
 			if nextIdx < len(c) {
 				c = c[nextIdx:]
@@ -0,0 +1,179 @@
+package httproutegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var testRequestMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+func makeCodeParameterValueLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return "[]byte(" + strconv.Quote(string(v)) + ")"
+	case int32:
+		return "int32(" + strconv.FormatInt(int64(v), 10) + ")"
+	case int64:
+		return "int64(" + strconv.FormatInt(v, 10) + ")"
+	case uint32:
+		return "uint32(" + strconv.FormatUint(uint64(v), 10) + ")"
+	case uint64:
+		return "uint64(" + strconv.FormatUint(v, 10) + ")"
+	}
+	return fmt.Sprintf("%#v", value)
+}
+
+func (inst *CodeGenerateInstance) testRecorderTypeName() string {
+	return inst.RouteMethodName + "TestRecorder"
+}
+
+func (inst *CodeGenerateInstance) testFunctionNameSuffix() string {
+	n := []rune(inst.RouteMethodName)
+	n[0] = unicode.ToTitle(n[0])
+	return string(n)
+}
+
+func (inst *CodeGenerateInstance) generateTestRecorderHandlerCode(recorderTypeName string) (result string) {
+	seenHandlerNames := make(map[string]bool)
+	for _, target := range inst.RouteTargets {
+		var paramDecls, paramNames string
+		for _, param := range target.Parameters {
+			paramDecls += ", " + param.Name + " " + param.Type
+			paramNames += ", " + param.Name
+		}
+		for _, handlerName := range target.HandlerNames() {
+			if seenHandlerNames[handlerName] {
+				continue
+			}
+			seenHandlerNames[handlerName] = true
+			result += "func (" + inst.ReceiverName + " *" + recorderTypeName + ") " + handlerName +
+				"(w http.ResponseWriter, req *http.Request, pathOffset int" + paramDecls + ") {\n" +
+				inst.ReceiverName + ".record(" + strconv.Quote(handlerName) + ", pathOffset" + paramNames + ")\n" +
+				"}\n\n"
+		}
+	}
+	if "" != inst.ObserverMethodName {
+		result += "func (" + inst.ReceiverName + " *" + recorderTypeName + ") " + inst.ObserverMethodName +
+			"(req *http.Request, ident " + inst.NamePrefix + "RouteIdent, template string) {\n" +
+			"}\n\n"
+	}
+	return
+}
+
+func (inst *CodeGenerateInstance) makeRouteTestCaseElement(methodName string, samplePath *RouteSamplePath, identName, handlerName string) string {
+	elementCode := "{" + httpMethodCodeMap[methodName] + ", " + strconv.Quote(samplePath.Path) + ", " + identName + ", " + strconv.Quote(handlerName)
+	if ("" != handlerName) && (len(samplePath.Parameters) > 0) {
+		var valueCodes []string
+		for _, param := range samplePath.Parameters {
+			valueCodes = append(valueCodes, makeCodeParameterValueLiteral(param.Value))
+		}
+		return elementCode + ", []interface{}{" + strings.Join(valueCodes, ", ") + "}},\n"
+	}
+	return elementCode + ", nil},\n"
+}
+
+// generateRouteTestCaseElements make test cases for sample paths of each route target.
+// Each sample path is tested with routed methods and one method which is not routed.
+func (inst *CodeGenerateInstance) generateRouteTestCaseElements() (result string) {
+	for _, target := range inst.RouteTargets {
+		for _, samplePath := range target.MakeSamplePaths() {
+			notAllowedMethod := ""
+			for _, methodName := range testRequestMethods {
+				handlerName := target.HandlerNameOfMethod(methodName)
+				if "" != handlerName {
+					result += inst.makeRouteTestCaseElement(methodName, samplePath, inst.makeRouteTargetIdentName(handlerName), handlerName)
+				} else if "" == notAllowedMethod {
+					notAllowedMethod = methodName
+				}
+			}
+			if "" != notAllowedMethod {
+				result += inst.makeRouteTestCaseElement(notAllowedMethod, samplePath, inst.NamePrefix+"RouteMethodNotAllowed", "")
+			}
+		}
+	}
+	return strings.TrimSuffix(result, "\n")
+}
+
+// generateRouteFuzzSeedElements make fuzz seeds from sample paths of each route target and truncated forms of them.
+func (inst *CodeGenerateInstance) generateRouteFuzzSeedElements() (result string) {
+	seenSeeds := make(map[string]bool)
+	addSeed := func(methodName, reqPath string) {
+		if k := methodName + " " + reqPath; !seenSeeds[k] {
+			seenSeeds[k] = true
+			result += "{" + httpMethodCodeMap[methodName] + ", " + strconv.Quote(reqPath) + "},\n"
+		}
+	}
+	addSeed(http.MethodGet, "/")
+	for _, target := range inst.RouteTargets {
+		methodName := target.Route.HandlerProfile.InvokeProfiles[0].RequestMethod
+		for _, samplePath := range target.MakeSamplePaths() {
+			addSeed(methodName, samplePath.Path)
+			addSeed(methodName, samplePath.Path[:(len(samplePath.Path)+1)/2])
+			addSeed(methodName, samplePath.Path+"/")
+		}
+	}
+	return strings.TrimSuffix(result, "\n")
+}
+
+func (inst *CodeGenerateInstance) generateTestCode() string {
+	recorderTypeName := inst.testRecorderTypeName()
+	importCode := "import (\n" +
+		"\"net/http\"\n" +
+		"\"net/http/httptest\"\n" +
+		"\"net/url\"\n" +
+		"\"reflect\"\n" +
+		"\"testing\"\n" +
+		")\n\n"
+	result := generatedCodeIndicatorLine +
+		"package " + inst.PackageName + "\n\n" +
+		importCode +
+		makeCodeTypeRouteTestRecorder(inst.ReceiverName, recorderTypeName) +
+		inst.generateTestRecorderHandlerCode(recorderTypeName) +
+		makeCodeFunctionRouteTest(inst.NamePrefix, recorderTypeName, inst.handlerDispatchTypeName(), inst.RouteMethodName, "Test"+inst.testFunctionNameSuffix(), inst.generateRouteTestCaseElements())
+	if inst.IncludeFuzzTarget {
+		result += makeCodeFunctionRouteFuzz(inst.NamePrefix, recorderTypeName, inst.handlerDispatchTypeName(), inst.RouteMethodName, "Fuzz"+inst.testFunctionNameSuffix(), inst.generateRouteFuzzSeedElements())
+	}
+	return result
+}
+
+// GenerateTestTo write formatted table-driven test of route method into given writer.
+// Fuzz target is included if IncludeFuzzTarget is set, which requires Go 1.18 or later to run.
+// The test runs routing logic of the code from GenerateTo() against a
+// recording fake of handler type, which requires UseHandlerInterface.
+// Must invoke after GenerateTo() or GenerateFile().
+func (inst *CodeGenerateInstance) GenerateTestTo(w io.Writer) (err error) {
+	if !inst.UseHandlerInterface {
+		return ErrTestRequiresHandlerInterface
+	}
+	codeText, err := formatCode([]byte(inst.generateTestCode()))
+	if nil != err {
+		return
+	}
+	_, err = w.Write(codeText)
+	return
+}
+
+// GenerateTest write formatted table-driven test of route method into given file atomically.
+// Must invoke after GenerateTo() or GenerateFile().
+func (inst *CodeGenerateInstance) GenerateTest(testFilePath string) (err error) {
+	var buf bytes.Buffer
+	if err = inst.GenerateTestTo(&buf); nil != err {
+		return
+	}
+	return writeFileAtomically(testFilePath, buf.Bytes())
+}
@@ -0,0 +1,95 @@
+package httproutegen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const testGenRouteConfig = `route:
+- c: 'pct/100%/{0-9, n int32}'
+  handler:
+    get: "percentNumber"
+- c: 'q/a b/{0-9, m int32}'
+  handler:
+    get: "spaceNumber"
+`
+
+const testGenHandlerCode = `package main
+
+import "net/http"
+
+type testGenHandler struct{}
+
+func (h *testGenHandler) percentNumber(w http.ResponseWriter, req *http.Request, pathOffset int, n int32) {}
+
+func (h *testGenHandler) spaceNumber(w http.ResponseWriter, req *http.Request, pathOffset int, m int32) {}
+
+func main() {}
+`
+
+func writeTestGenFile(t *testing.T, folderPath, fileName string, content []byte) string {
+	filePath := filepath.Join(folderPath, fileName)
+	if err := ioutil.WriteFile(filePath, content, 0644); nil != err {
+		t.Fatalf("cannot write %s: %v", fileName, err)
+	}
+	return filePath
+}
+
+// TestGenerateTestEscapedPath run generated test of routes which have
+// characters httptest.NewRequest() cannot take in request path.
+func TestGenerateTestEscapedPath(t *testing.T) {
+	goExecPath, err := exec.LookPath("go")
+	if nil != err {
+		t.Skip("go command is not available")
+	}
+	if testing.Short() {
+		t.Skip("skip running generated test in short mode")
+	}
+	moduleDirPath, err := ioutil.TempDir("", "httproutegen-testgen-")
+	if nil != err {
+		t.Fatalf("cannot create module folder: %v", err)
+	}
+	defer os.RemoveAll(moduleDirPath)
+	configFilePath := writeTestGenFile(t, moduleDirPath, "route.yaml", []byte(testGenRouteConfig))
+	writeTestGenFile(t, moduleDirPath, "go.mod", []byte("module testgen\n\ngo 1.12\n"))
+	writeTestGenFile(t, moduleDirPath, "handler.go", []byte(testGenHandlerCode))
+	rootRouteEntry, err := LoadYAML(configFilePath)
+	if nil != err {
+		t.Fatalf("cannot load route configuration: %v", err)
+	}
+	fanoutInstance, err := MakeFanoutInstance(rootRouteEntry)
+	if nil != err {
+		t.Fatalf("cannot create fanout instance: %v", err)
+	}
+	if err = fanoutInstance.ExpandFanout(); nil != err {
+		t.Fatalf("cannot expand fanout instance: %v", err)
+	}
+	inst, err := NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, nil)
+	if nil != err {
+		t.Fatalf("cannot create code generation instance: %v", err)
+	}
+	inst.PackageName = "main"
+	inst.ReceiverName = "h"
+	inst.HandlerTypeName = "testGenHandler"
+	inst.RouteMethodName = "routeRequest"
+	inst.UseHandlerInterface = true
+	var codeBuf, testBuf bytes.Buffer
+	if err = inst.GenerateTo(&codeBuf); nil != err {
+		t.Fatalf("cannot generate route method: %v", err)
+	}
+	if err = inst.GenerateTestTo(&testBuf); nil != err {
+		t.Fatalf("cannot generate test of route method: %v", err)
+	}
+	writeTestGenFile(t, moduleDirPath, "handler_route.go", codeBuf.Bytes())
+	writeTestGenFile(t, moduleDirPath, "handler_route_test.go", testBuf.Bytes())
+	cmd := exec.Command(goExecPath, "test", ".")
+	cmd.Dir = moduleDirPath
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if output, err := cmd.CombinedOutput(); nil != err {
+		t.Fatalf("generated test failed: %v\n%s", err, output)
+	}
+}
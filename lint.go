@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+// lintRouteConfiguration log problems found in route configuration and return count of findings.
+func lintRouteConfiguration(rootRouteEntry *httproutegen.RouteEntry, diagnostics httproutegen.Diagnostics) (findingCount int, err error) {
+	targets, err := httproutegen.CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		return
+	}
+	findings := httproutegen.LintRouteTargets(targets)
+	for _, finding := range findings {
+		log.Printf("LINT: %v", finding)
+	}
+	fanoutInstance, err := httproutegen.MakeFanoutInstanceWithDiagnostics(rootRouteEntry, diagnostics)
+	if nil != err {
+		return
+	}
+	if err = fanoutInstance.ExpandFanout(); nil != err {
+		return
+	}
+	fanoutFindings := httproutegen.LintFanoutInstance(targets, fanoutInstance)
+	for _, finding := range fanoutFindings {
+		log.Printf("LINT: %v", finding)
+	}
+	return len(findings) + len(fanoutFindings), nil
+}
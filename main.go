@@ -7,29 +7,144 @@ import (
 	"github.com/yinyin/go-http-route-gen/httproutegen"
 )
 
+func applyCodeGenerateParameters(codeGenInst *httproutegen.CodeGenerateInstance, param *commandParameters) {
+	codeGenInst.PackageName = param.PackageName
+	codeGenInst.ReceiverName = param.ReceiverName
+	codeGenInst.HandlerTypeName = param.HandlerTypeName
+	codeGenInst.RouteMethodName = param.RouteMethodName
+	codeGenInst.NamePrefix = param.GenNamePrefix
+	codeGenInst.UseSharedRuntime = ("" != param.RuntimeFileName)
+	codeGenInst.UseRouteProgram = (backendRouteProgram == param.Backend)
+	codeGenInst.SwitchMinWidth = param.SwitchWidth
+	codeGenInst.BinarySearchMinWidth = param.BinarySearchWidth
+	codeGenInst.SplitAreas = param.SplitAreas
+	codeGenInst.SplitLineLimit = param.SplitLineLimit
+	codeGenInst.CountRouteHits = param.CountHits
+	codeGenInst.TimeRouteHandlers = param.TimeHandlers
+	codeGenInst.ObserverMethodName = param.ObserverName
+	codeGenInst.IncludeFuzzTarget = param.FuzzTest
+	codeGenInst.UseHandlerInterface = param.HandlerInterface
+}
+
 func main() {
-	inputFilePath, outputFilePath, packageName, receiverName, handlerTypeName, routeMethodName, genNamePrefix, dumpFanoutContent, err := parseCommandParam()
+	param, err := parseCommandParam()
 	if nil != err {
 		log.Fatalf("ERR: cannot have required parameters: %v", err)
 		return
 	}
+	inputFilePath := param.InputFilePath
+	outputFilePath := param.OutputFilePath
 	log.Printf("Input: [%v].", inputFilePath)
-	log.Printf("Output: [%v]", outputFilePath)
-	log.Printf("Route Method: (%s *%s) %s() (%sRouteIdent).", receiverName, handlerTypeName, routeMethodName, genNamePrefix)
-	rootRouteEntry, err := httproutegen.LoadYAML(inputFilePath)
+	if param.ImportOpenAPI {
+		if err = importOpenAPIDocument(inputFilePath, outputFilePath); nil != err {
+			log.Fatalf("ERR: cannot import OpenAPI document [%s]: %v", inputFilePath, err)
+		}
+		log.Printf("Route configuration: [%v]", outputFilePath)
+		return
+	}
+	rootRouteEntry, err := httproutegen.LoadYAMLWithDiagnostics(inputFilePath, param.diagnostics)
 	if nil != err {
 		log.Fatalf("ERR: cannot load route configuration [%s]: %v", inputFilePath, err)
 		return
 	}
-	fanoutInstance, err := httproutegen.MakeFanoutInstance(rootRouteEntry)
+	param.failOnStrictDiagnostics()
+	param.applyGeneratorOptions(rootRouteEntry.Generator)
+	if err = param.checkBackend(); nil != err {
+		log.Fatalf("ERR: cannot have code generation backend of route configuration [%s]: %v", inputFilePath, err)
+		return
+	}
+	if err = param.checkTestOutput(); nil != err {
+		log.Fatalf("ERR: cannot generate test of route configuration [%s]: %v", inputFilePath, err)
+		return
+	}
+	if len(rootRouteEntry.Routers) > 0 {
+		runRouters(inputFilePath, rootRouteEntry, param)
+		return
+	}
+	if param.Lint {
+		findingCount, err := lintRouteConfiguration(rootRouteEntry, param.diagnostics)
+		if nil != err {
+			log.Fatalf("ERR: cannot lint route configuration [%s]: %v", inputFilePath, err)
+			return
+		}
+		if findingCount > 0 {
+			log.Fatalf("ERR: %d lint finding(s) in route configuration [%s]", findingCount, inputFilePath)
+			return
+		}
+		log.Print("Lint: no finding.")
+	}
+	if param.CheckHandlers {
+		mismatchCount, err := checkHandlerSignatures(inputFilePath, rootRouteEntry, outputFilePath, param.HandlerTypeName, param.TestFilePath)
+		if nil != err {
+			log.Fatalf("ERR: cannot check handler signatures of %s: %v", param.HandlerTypeName, err)
+			return
+		}
+		if mismatchCount > 0 {
+			log.Fatalf("ERR: %d handler method(s) of %s do not fit route configuration [%s]", mismatchCount, param.HandlerTypeName, inputFilePath)
+			return
+		}
+		log.Printf("Handler check: all handler methods of %s fit route configuration.", param.HandlerTypeName)
+		return
+	}
+	if "" != param.OpenAPIFilePath {
+		if err = exportOpenAPIDocument(param.OpenAPIFilePath, inputFilePath, rootRouteEntry); nil != err {
+			log.Fatalf("ERR: cannot export OpenAPI document [%s]: %v", param.OpenAPIFilePath, err)
+			return
+		}
+		log.Printf("OpenAPI: [%v]", param.OpenAPIFilePath)
+	}
+	if "" != param.DocumentFilePath {
+		if err = writeRouteDocument(param.DocumentFilePath, inputFilePath, rootRouteEntry); nil != err {
+			log.Fatalf("ERR: cannot write route document [%s]: %v", param.DocumentFilePath, err)
+			return
+		}
+		log.Printf("Document: [%v]", param.DocumentFilePath)
+	}
+	if ("" == outputFilePath) && ("" == param.DOTFilePath) {
+		return
+	}
+	fanoutInstance, err := httproutegen.MakeFanoutInstanceWithDiagnostics(rootRouteEntry, param.diagnostics)
 	if nil != err {
 		log.Fatalf("ERR: cannot create fanout instance from root route entry: %v", err)
 		return
 	}
+	fanoutInstance.DisablePrefixDigest64 = param.NoPrefixDigest64
 	if err = fanoutInstance.ExpandFanout(); nil != err {
 		log.Fatalf("ERR: cannot expand fanout instance: %v", err)
 		return
 	}
+	param.failOnStrictDiagnostics()
+	if "" != param.DOTFilePath {
+		if err = writeFanoutForkDOT(param.DOTFilePath, fanoutInstance.RootFanoutFork); nil != err {
+			log.Fatalf("ERR: cannot write DOT graph [%s]: %v", param.DOTFilePath, err)
+			return
+		}
+		log.Printf("DOT graph: [%v]", param.DOTFilePath)
+	}
+	if "" == outputFilePath {
+		return
+	}
+	if param.CheckOutput {
+		upToDate, err := checkGeneratedCode(outputFilePath, fanoutInstance, param)
+		if nil != err {
+			log.Fatalf("ERR: cannot check output file [%s]: %v", outputFilePath, err)
+			return
+		}
+		if runtimeFilePath := param.runtimeFilePath(); ("" != runtimeFilePath) && upToDate {
+			if upToDate, err = checkRuntimeCode(runtimeFilePath, param.PackageName); nil != err {
+				log.Fatalf("ERR: cannot check runtime file [%s]: %v", runtimeFilePath, err)
+				return
+			}
+		}
+		if !upToDate {
+			log.Fatalf("ERR: output file [%s] is not up to date with route configuration [%s]", outputFilePath, inputFilePath)
+			return
+		}
+		log.Printf("Check: output file [%s] is up to date.", outputFilePath)
+		return
+	}
+	log.Printf("Output: [%v]", outputFilePath)
+	log.Printf("Route Method: (%s *%s) %s() (%sRouteIdent).", param.ReceiverName, param.HandlerTypeName, param.RouteMethodName, param.GenNamePrefix)
 	if fanoutJSONText, err := json.MarshalIndent(fanoutInstance, "", "  "); nil != err {
 		log.Fatalf("ERR: cannot encode root fanout into JSON: %v", err)
 	} else if ':' == outputFilePath[0] {
@@ -37,20 +152,24 @@ func main() {
 		err = runHTTPService(outputFilePath, fanoutJSONText)
 		log.Printf("HTTP stopped: %v", err)
 		return
-	} else if dumpFanoutContent {
+	} else if param.DumpFanoutContent {
 		log.Print(string(fanoutJSONText))
 	}
-	codeGenInst, err := httproutegen.OpenCodeGenerateInstance(outputFilePath, fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope)
+	codeGenInst, err := httproutegen.NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, param.diagnostics)
 	if nil != err {
-		log.Fatalf("ERR: cannot open code generation instance: %v", err)
+		log.Fatalf("ERR: cannot create code generation instance: %v", err)
 		return
 	}
-	defer codeGenInst.Close()
-	codeGenInst.PackageName = packageName
-	codeGenInst.ReceiverName = receiverName
-	codeGenInst.HandlerTypeName = handlerTypeName
-	codeGenInst.RouteMethodName = routeMethodName
-	codeGenInst.NamePrefix = genNamePrefix
-	err = codeGenInst.Generate()
+	defer param.failOnStrictDiagnostics()
+	applyCodeGenerateParameters(codeGenInst, param)
+	err = codeGenInst.GenerateFile(outputFilePath)
 	log.Printf("Code generate stopped: %v", err)
+	if runtimeFilePath := param.runtimeFilePath(); (nil == err) && ("" != runtimeFilePath) {
+		err = httproutegen.GenerateRuntimeFile(runtimeFilePath, param.PackageName)
+		log.Printf("Runtime generate [%v] stopped: %v", runtimeFilePath, err)
+	}
+	if (nil == err) && ("" != param.TestFilePath) {
+		err = codeGenInst.GenerateTest(param.TestFilePath)
+		log.Printf("Test generate [%v] stopped: %v", param.TestFilePath, err)
+	}
 }
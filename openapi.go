@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+func exportOpenAPIDocument(openAPIFilePath, inputFilePath string, rootRouteEntry *httproutegen.RouteEntry) (err error) {
+	targets, err := httproutegen.CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		return
+	}
+	title := strings.TrimSuffix(filepath.Base(inputFilePath), filepath.Ext(inputFilePath))
+	doc, err := httproutegen.MakeOpenAPIDocument(title, "1.0.0", targets)
+	if nil != err {
+		return
+	}
+	var docContent []byte
+	if strings.ToLower(filepath.Ext(openAPIFilePath)) == ".json" {
+		docContent, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		docContent, err = yaml.Marshal(doc)
+	}
+	if nil != err {
+		return
+	}
+	return ioutil.WriteFile(openAPIFilePath, docContent, 0644)
+}
+
+func importOpenAPIDocument(inputFilePath, outputFilePath string) (err error) {
+	doc, err := httproutegen.LoadOpenAPI(inputFilePath)
+	if nil != err {
+		return
+	}
+	rootRouteEntry, err := httproutegen.MakeRouteEntryFromOpenAPI(doc)
+	if nil != err {
+		return
+	}
+	if err = httproutegen.SaveYAML(outputFilePath, rootRouteEntry); nil != err {
+		return
+	}
+	_, err = httproutegen.LoadYAML(outputFilePath)
+	return
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+func writeRouteDocument(documentFilePath, inputFilePath string, rootRouteEntry *httproutegen.RouteEntry) (err error) {
+	targets, err := httproutegen.CollectRouteTargets(rootRouteEntry)
+	if nil != err {
+		return
+	}
+	fp, err := os.Create(documentFilePath)
+	if nil != err {
+		return
+	}
+	defer fp.Close()
+	return httproutegen.WriteRouteDocument(fp, "Routes of "+filepath.Base(inputFilePath), targets)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"path/filepath"
+
+	"github.com/yinyin/go-http-route-gen/httproutegen"
+)
+
+// ErrRoutersNotSupported indicates requested output is not available for route configuration with routers.
+var ErrRoutersNotSupported = errors.New("OpenAPI, document, DOT, test and HTTP outputs are not supported with routers")
+
+type routerJob struct {
+	Name           string
+	RouteEntry     *httproutegen.RouteEntry
+	Param          commandParameters
+	OutputFilePath string
+}
+
+// makeRouterJobs take generator options of each router over the top level options.
+// Options given in command line still have the highest priority.
+func makeRouterJobs(rootRouteEntry *httproutegen.RouteEntry, param *commandParameters) (jobs []*routerJob, err error) {
+	if ("" != param.OpenAPIFilePath) || ("" != param.DocumentFilePath) || ("" != param.DOTFilePath) || ("" != param.TestFilePath) {
+		return nil, ErrRoutersNotSupported
+	}
+	if ("" != param.OutputFilePath) && (':' == param.OutputFilePath[0]) {
+		return nil, ErrRoutersNotSupported
+	}
+	for _, router := range rootRouteEntry.Routers {
+		job := &routerJob{
+			Name:           router.Name,
+			RouteEntry:     &router.RouteEntry,
+			Param:          *param,
+			OutputFilePath: param.OutputFilePath,
+		}
+		job.Param.applyGeneratorOptions(router.Generator)
+		if err = job.Param.checkBackend(); nil != err {
+			return nil, err
+		}
+		if ("" != router.OutputFile) && ("" != param.OutputFilePath) {
+			if filepath.IsAbs(router.OutputFile) {
+				job.OutputFilePath = router.OutputFile
+			} else {
+				job.OutputFilePath = filepath.Join(filepath.Dir(param.OutputFilePath), router.OutputFile)
+			}
+		}
+		job.Param.OutputFilePath = job.OutputFilePath
+		jobs = append(jobs, job)
+	}
+	return
+}
+
+func (job *routerJob) newCodeGenerateInstance() (codeGenInst *httproutegen.CodeGenerateInstance, err error) {
+	fanoutInstance, err := httproutegen.MakeFanoutInstanceWithDiagnostics(job.RouteEntry, job.Param.diagnostics)
+	if nil != err {
+		return
+	}
+	fanoutInstance.DisablePrefixDigest64 = job.Param.NoPrefixDigest64
+	if err = fanoutInstance.ExpandFanout(); nil != err {
+		return
+	}
+	if codeGenInst, err = httproutegen.NewCodeGenerateInstance(fanoutInstance.RootFanoutFork, &fanoutInstance.InstanceSymbolScope, job.Param.diagnostics); nil != err {
+		return
+	}
+	applyCodeGenerateParameters(codeGenInst, &job.Param)
+	return
+}
+
+// groupRouterJobsByPackage return code generation instances of routers which are
+// generated into the same package, that is the same folder and package name.
+func groupRouterJobsByPackage(jobs []*routerJob, codeGenInsts []*httproutegen.CodeGenerateInstance) (groups [][]*httproutegen.CodeGenerateInstance) {
+	groupIndexes := make(map[string]int)
+	for idx, job := range jobs {
+		packageKey := filepath.Dir(job.OutputFilePath) + "\x00" + job.Param.PackageName
+		groupIndex, ok := groupIndexes[packageKey]
+		if !ok {
+			groupIndex = len(groups)
+			groupIndexes[packageKey] = groupIndex
+			groups = append(groups, nil)
+		}
+		groups[groupIndex] = append(groups[groupIndex], codeGenInsts[idx])
+	}
+	return
+}
+
+// groupRouterJobsByOutput return output file paths in the order of first appearance
+// and code generation instances of routers for each output file.
+func groupRouterJobsByOutput(jobs []*routerJob, codeGenInsts []*httproutegen.CodeGenerateInstance) (outputFilePaths []string, groups map[string][]*httproutegen.CodeGenerateInstance) {
+	groups = make(map[string][]*httproutegen.CodeGenerateInstance)
+	for idx, job := range jobs {
+		if _, ok := groups[job.OutputFilePath]; !ok {
+			outputFilePaths = append(outputFilePaths, job.OutputFilePath)
+		}
+		groups[job.OutputFilePath] = append(groups[job.OutputFilePath], codeGenInsts[idx])
+	}
+	return
+}
+
+// routerRuntimeFiles return distinct shared runtime file paths of routers
+// and package name of each runtime file.
+func routerRuntimeFiles(jobs []*routerJob) (runtimeFilePaths []string, packageNames map[string]string) {
+	packageNames = make(map[string]string)
+	for _, job := range jobs {
+		runtimeFilePath := job.Param.runtimeFilePath()
+		if "" == runtimeFilePath {
+			continue
+		}
+		if _, ok := packageNames[runtimeFilePath]; !ok {
+			runtimeFilePaths = append(runtimeFilePaths, runtimeFilePath)
+			packageNames[runtimeFilePath] = job.Param.PackageName
+		}
+	}
+	return
+}
+
+// runRouters lint, check or generate code of each router declared in route configuration.
+func runRouters(inputFilePath string, rootRouteEntry *httproutegen.RouteEntry, param *commandParameters) {
+	jobs, err := makeRouterJobs(rootRouteEntry, param)
+	if nil != err {
+		log.Fatalf("ERR: cannot prepare routers of route configuration [%s]: %v", inputFilePath, err)
+		return
+	}
+	if param.Lint {
+		totalFindingCount := 0
+		for _, job := range jobs {
+			findingCount, err := lintRouteConfiguration(job.RouteEntry, job.Param.diagnostics)
+			if nil != err {
+				log.Fatalf("ERR: cannot lint router %s of route configuration [%s]: %v", job.Name, inputFilePath, err)
+				return
+			}
+			totalFindingCount += findingCount
+		}
+		if totalFindingCount > 0 {
+			log.Fatalf("ERR: %d lint finding(s) in route configuration [%s]", totalFindingCount, inputFilePath)
+			return
+		}
+		log.Print("Lint: no finding.")
+	}
+	if param.CheckHandlers {
+		var outputFilePaths []string
+		for _, job := range jobs {
+			outputFilePaths = append(outputFilePaths, job.OutputFilePath)
+		}
+		totalMismatchCount := 0
+		for _, job := range jobs {
+			mismatchCount, err := checkHandlerSignatures(inputFilePath, job.RouteEntry, job.OutputFilePath, job.Param.HandlerTypeName, outputFilePaths...)
+			if nil != err {
+				log.Fatalf("ERR: cannot check handler signatures of %s (router %s): %v", job.Param.HandlerTypeName, job.Name, err)
+				return
+			}
+			totalMismatchCount += mismatchCount
+		}
+		if totalMismatchCount > 0 {
+			log.Fatalf("ERR: %d handler method(s) do not fit route configuration [%s]", totalMismatchCount, inputFilePath)
+			return
+		}
+		log.Print("Handler check: all handler methods fit route configuration.")
+		return
+	}
+	if "" == param.OutputFilePath {
+		return
+	}
+	var codeGenInsts []*httproutegen.CodeGenerateInstance
+	for _, job := range jobs {
+		codeGenInst, err := job.newCodeGenerateInstance()
+		if nil != err {
+			log.Fatalf("ERR: cannot create code generation instance of router %s: %v", job.Name, err)
+			return
+		}
+		codeGenInsts = append(codeGenInsts, codeGenInst)
+	}
+	param.failOnStrictDiagnostics()
+	defer param.failOnStrictDiagnostics()
+	for _, packageInsts := range groupRouterJobsByPackage(jobs, codeGenInsts) {
+		if err = httproutegen.VerifyRouterInstances(packageInsts); nil != err {
+			log.Fatalf("ERR: routers of route configuration [%s] conflict: %v", inputFilePath, err)
+			return
+		}
+	}
+	outputFilePaths, groups := groupRouterJobsByOutput(jobs, codeGenInsts)
+	runtimeFilePaths, runtimePackageNames := routerRuntimeFiles(jobs)
+	if param.CheckOutput {
+		upToDate := true
+		for _, outputFilePath := range outputFilePaths {
+			var expectContent bytes.Buffer
+			if err = httproutegen.GenerateRoutersTo(&expectContent, groups[outputFilePath]...); nil != err {
+				log.Fatalf("ERR: cannot check output file [%s]: %v", outputFilePath, err)
+				return
+			}
+			fileUpToDate, err := checkOutputContent(outputFilePath, expectContent.Bytes())
+			if nil != err {
+				log.Fatalf("ERR: cannot check output file [%s]: %v", outputFilePath, err)
+				return
+			}
+			if fileUpToDate {
+				log.Printf("Check: output file [%s] is up to date.", outputFilePath)
+			}
+			upToDate = upToDate && fileUpToDate
+		}
+		for _, runtimeFilePath := range runtimeFilePaths {
+			fileUpToDate, err := checkRuntimeCode(runtimeFilePath, runtimePackageNames[runtimeFilePath])
+			if nil != err {
+				log.Fatalf("ERR: cannot check runtime file [%s]: %v", runtimeFilePath, err)
+				return
+			}
+			if fileUpToDate {
+				log.Printf("Check: runtime file [%s] is up to date.", runtimeFilePath)
+			}
+			upToDate = upToDate && fileUpToDate
+		}
+		if !upToDate {
+			log.Fatalf("ERR: output files are not up to date with route configuration [%s]", inputFilePath)
+		}
+		return
+	}
+	for _, job := range jobs {
+		log.Printf("Router %s: (%s *%s) %s() (%sRouteIdent) => [%v].", job.Name, job.Param.ReceiverName, job.Param.HandlerTypeName, job.Param.RouteMethodName, job.Param.GenNamePrefix, job.OutputFilePath)
+	}
+	for _, outputFilePath := range outputFilePaths {
+		err = httproutegen.GenerateRoutersFile(outputFilePath, groups[outputFilePath]...)
+		log.Printf("Code generate [%v] stopped: %v", outputFilePath, err)
+		if nil != err {
+			return
+		}
+	}
+	for _, runtimeFilePath := range runtimeFilePaths {
+		err = httproutegen.GenerateRuntimeFile(runtimeFilePath, runtimePackageNames[runtimeFilePath])
+		log.Printf("Runtime generate [%v] stopped: %v", runtimeFilePath, err)
+		if nil != err {
+			return
+		}
+	}
+}
@@ -5,6 +5,7 @@ package main
 import (
 	"errors"
 	"net/http"
+	"strconv"
 )
 
 // RouteIdent define type for route identifier.
@@ -32,24 +33,205 @@ const (
 	RouteToUniqueJSON
 )
 
+// String return name of route identifier.
+func (ident RouteIdent) String() string {
+	switch ident {
+	case RouteNone:
+		return "RouteNone"
+	case RouteIncomplete:
+		return "RouteIncomplete"
+	case RouteMethodNotAllowed:
+		return "RouteMethodNotAllowed"
+	case RouteError:
+		return "RouteError"
+	case RouteMissSampleAdmin:
+		return "RouteMissSampleAdmin"
+	case RouteMissDebugSample:
+		return "RouteMissDebugSample"
+	case RouteSuccess:
+		return "RouteSuccess"
+	case RouteToQueryProduct:
+		return "RouteToQueryProduct"
+	case RouteToDownloadProduct:
+		return "RouteToDownloadProduct"
+	case RouteToListProducts:
+		return "RouteToListProducts"
+	case RouteToShowProduct:
+		return "RouteToShowProduct"
+	case RouteToSampleData:
+		return "RouteToSampleData"
+	case RouteToDebugText:
+		return "RouteToDebugText"
+	case RouteToDebugJSON:
+		return "RouteToDebugJSON"
+	case RouteToExactText:
+		return "RouteToExactText"
+	case RouteToDebugNumber:
+		return "RouteToDebugNumber"
+	case RouteToUniqueText:
+		return "RouteToUniqueText"
+	case RouteToUniqueJSON:
+		return "RouteToUniqueJSON"
+	}
+	return "RouteIdent(" + strconv.FormatInt(int64(ident), 10) + ")"
+}
+
+// Template return path template of routed target.
+// Empty string will be returned for identifiers which are not route target.
+func (ident RouteIdent) Template() string {
+	switch ident {
+	case RouteToQueryProduct:
+		return "/sample-api/query/{productName}"
+	case RouteToDownloadProduct:
+		return "/sample-api/download/{sessionId}/{targetId}"
+	case RouteToListProducts:
+		return "/sample-admin-api/products"
+	case RouteToShowProduct:
+		return "/sample-admin-api/product/{productId}"
+	case RouteToSampleData:
+		return "/sample-data"
+	case RouteToDebugText:
+		return "/sample-debug/text"
+	case RouteToDebugJSON:
+		return "/sample-debug/json"
+	case RouteToExactText:
+		return "/sample-exact/text"
+	case RouteToDebugNumber:
+		return "/debug-sample/text/%7B{num}%7D/{hex1}/{hex2}"
+	case RouteToUniqueText:
+		return "/unique-path/text/{num}"
+	case RouteToUniqueJSON:
+		return "/unique-path/json/{num}"
+	}
+	return ""
+}
+
+// RouteDescriptor describe one route target for runtime introspection.
+type RouteDescriptor struct {
+	Ident          RouteIdent
+	AreaName       string
+	Methods        []string
+	HandlerName    string
+	Pattern        string
+	Template       string
+	ParameterNames []string
+	ParameterTypes []string
+}
+
+// RouteDescriptors is the list of route targets.
+var RouteDescriptors = []RouteDescriptor{
+	{
+		Ident:          RouteToQueryProduct,
+		Methods:        []string{http.MethodGet, http.MethodPost},
+		HandlerName:    "queryProduct",
+		Pattern:        "/sample-api/query/{a-zA-Z0-9\\-, productName string}",
+		Template:       "/sample-api/query/{productName}",
+		ParameterNames: []string{"productName"},
+		ParameterTypes: []string{"string"},
+	},
+	{
+		Ident:          RouteToDownloadProduct,
+		Methods:        []string{http.MethodGet},
+		HandlerName:    "downloadProduct",
+		Pattern:        "/sample-api/download/{0-9, sessionId int64}/{0-9, targetId int64}",
+		Template:       "/sample-api/download/{sessionId}/{targetId}",
+		ParameterNames: []string{"sessionId", "targetId"},
+		ParameterTypes: []string{"int64", "int64"},
+	},
+	{
+		Ident:       RouteToListProducts,
+		AreaName:    "SampleAdmin",
+		Methods:     []string{http.MethodGet},
+		HandlerName: "listProducts",
+		Pattern:     "/sample-admin-api/products",
+		Template:    "/sample-admin-api/products",
+	},
+	{
+		Ident:          RouteToShowProduct,
+		AreaName:       "SampleAdmin",
+		Methods:        []string{http.MethodGet},
+		HandlerName:    "showProduct",
+		Pattern:        "/sample-admin-api/product/{0-9, productId int64}",
+		Template:       "/sample-admin-api/product/{productId}",
+		ParameterNames: []string{"productId"},
+		ParameterTypes: []string{"int64"},
+	},
+	{
+		Ident:       RouteToSampleData,
+		Methods:     []string{http.MethodGet},
+		HandlerName: "sampleData",
+		Pattern:     "/sample-data",
+		Template:    "/sample-data",
+	},
+	{
+		Ident:       RouteToExactText,
+		Methods:     []string{http.MethodGet},
+		HandlerName: "exactText",
+		Pattern:     "/sample-exact/text",
+		Template:    "/sample-exact/text",
+	},
+	{
+		Ident:       RouteToDebugText,
+		Methods:     []string{http.MethodGet},
+		HandlerName: "debugText",
+		Pattern:     "/sample-debug/text",
+		Template:    "/sample-debug/text",
+	},
+	{
+		Ident:       RouteToDebugJSON,
+		Methods:     []string{http.MethodGet},
+		HandlerName: "debugJSON",
+		Pattern:     "/sample-debug/json",
+		Template:    "/sample-debug/json",
+	},
+	{
+		Ident:          RouteToDebugNumber,
+		AreaName:       "DebugSample",
+		Methods:        []string{http.MethodGet},
+		HandlerName:    "debugNumber",
+		Pattern:        "/debug-sample/text/\\{{0-9, num int32}\\}/{0-9A-Fa-f, hex1 int32}/{0-9A-Fa-f, hex2 uint32}",
+		Template:       "/debug-sample/text/%7B{num}%7D/{hex1}/{hex2}",
+		ParameterNames: []string{"num", "hex1", "hex2"},
+		ParameterTypes: []string{"int32", "int32", "uint32"},
+	},
+	{
+		Ident:          RouteToUniqueText,
+		Methods:        []string{http.MethodGet},
+		HandlerName:    "uniqueText",
+		Pattern:        "/unique-path/text/{0-9, num int32}",
+		Template:       "/unique-path/text/{num}",
+		ParameterNames: []string{"num"},
+		ParameterTypes: []string{"int32"},
+	},
+	{
+		Ident:          RouteToUniqueJSON,
+		Methods:        []string{http.MethodGet},
+		HandlerName:    "uniqueJSON",
+		Pattern:        "/unique-path/json/{0-9, num int32}",
+		Template:       "/unique-path/json/{num}",
+		ParameterNames: []string{"num"},
+		ParameterTypes: []string{"int32"},
+	},
+}
+
 var errFragmentSmallerThanExpect = errors.New("remaining path fragment smaller than expect")
 
 var filterMaskStringRxSeq000 = [...]uint32{0xfff01ff9, 0xfff03fff, 0x3fff, 0x0}
 
 func extractStringRxSeq000(v string, offset, bound int) (string, int, error) {
-	var result []byte
-	for idx := offset; idx < bound; idx++ {
-		ch := v[idx]
-		moved := ch - 0x2d
+	if offset > bound {
+		offset = bound
+	}
+	idx := offset
+	for ; idx < bound; idx++ {
+		moved := v[idx] - 0x2d
 		page := (moved >> 5) & 0x3
 		nbit := moved & 0x1F
-		if 0 != (filterMaskStringRxSeq000[page] & (1 << nbit)) {
-			result = append(result, ch)
-			continue
+		if 0 == (filterMaskStringRxSeq000[page] & (1 << nbit)) {
+			break
 		}
-		return string(result), idx, nil
 	}
-	return string(result), bound, nil
+	return v[offset:idx], idx, nil
 }
 
 func extractInt64BuiltInR02(v string, offset, bound int) (int64, int, error) {
@@ -139,6 +321,20 @@ func computePrefixMatchingDigest32(path string, offset, bound, length int) (uint
 	return digest, offset, nil
 }
 
+func computePrefixMatchingDigest64(path string, offset, bound, length int) (uint64, int, error) {
+	b := offset + length
+	if b > bound {
+		return 0, offset, errFragmentSmallerThanExpect
+	}
+	var digest uint64
+	for offset < b {
+		ch := path[offset]
+		offset++
+		digest = (digest << 8) | uint64(ch)
+	}
+	return digest, offset, nil
+}
+
 func (h *sampleHandler) routeRequest(w http.ResponseWriter, req *http.Request) (RouteIdent, error) {
 	reqPath := req.URL.Path
 	reqPathOffset := 0
@@ -156,6 +352,7 @@ func (h *sampleHandler) routeRequest(w http.ResponseWriter, req *http.Request) (
 	var err error
 	_ = err
 	var digest32 uint32
+	var digest64 uint64
 	if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, reqPathOffset, reqPathBound, 4); nil != err {
 		return RouteError, err
 	} else if digest32 == 0x73616d70 {
@@ -256,23 +453,28 @@ func (h *sampleHandler) routeRequest(w http.ResponseWriter, req *http.Request) (
 				}
 			}
 		} else if digest32 == 0x6c652d65 {
-			if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, reqPathOffset, reqPathBound, 4); nil != err {
-				return RouteError, err
-			} else if digest32 == 0x78616374 {
+			if reqPathOffset+8 > reqPathBound {
 				if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, reqPathOffset, reqPathBound, 4); nil != err {
 					return RouteError, err
-				} else if digest32 == 0x2f746578 {
-					if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, reqPathOffset, reqPathBound, 1); nil != err {
-						return RouteError, err
-					} else if digest32 == 0x74 {
-						switch req.Method {
-						case http.MethodGet:
-							h.exactText(w, req, reqPathOffset)
-							return RouteToExactText, nil
-						}
-						http.Error(w, "not allow", http.StatusMethodNotAllowed)
-						return RouteMethodNotAllowed, nil
+				}
+				switch digest32 {
+				case 0x78616374:
+					return RouteError, errFragmentSmallerThanExpect
+				}
+				digest64 = 0
+			} else if digest64, reqPathOffset, err = computePrefixMatchingDigest64(reqPath, reqPathOffset, reqPathBound, 8); nil != err {
+				return RouteError, err
+			} else if digest64 == 0x786163742f746578 {
+				if digest32, reqPathOffset, err = computePrefixMatchingDigest32(reqPath, reqPathOffset, reqPathBound, 1); nil != err {
+					return RouteError, err
+				} else if digest32 == 0x74 {
+					switch req.Method {
+					case http.MethodGet:
+						h.exactText(w, req, reqPathOffset)
+						return RouteToExactText, nil
 					}
+					http.Error(w, "not allow", http.StatusMethodNotAllowed)
+					return RouteMethodNotAllowed, nil
 				}
 			}
 		}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const unifiedDiffContextLines = 3
+
+type diffOperation struct {
+	kind   byte // one of ' ', '-' or '+'
+	aIndex int
+	bIndex int
+}
+
+func splitDiffLines(text string) (lines []string) {
+	lines = strings.SplitAfter(text, "\n")
+	if (len(lines) > 0) && ("" == lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return
+}
+
+// diffLines compute edit script from a to b with longest common subsequence.
+// Common heading and trailing lines are trimmed, and the remaining lines are
+// compared with Hirschberg's algorithm which takes space linear to input size.
+func diffLines(a, b []string) (ops []diffOperation) {
+	prefixLen := 0
+	for (prefixLen < len(a)) && (prefixLen < len(b)) && (a[prefixLen] == b[prefixLen]) {
+		prefixLen++
+	}
+	suffixLen := 0
+	for (suffixLen < len(a)-prefixLen) && (suffixLen < len(b)-prefixLen) && (a[len(a)-1-suffixLen] == b[len(b)-1-suffixLen]) {
+		suffixLen++
+	}
+	for i := 0; i < prefixLen; i++ {
+		ops = append(ops, diffOperation{kind: ' ', aIndex: i, bIndex: i})
+	}
+	ops = diffLinesLinearSpace(ops, a[prefixLen:len(a)-suffixLen], b[prefixLen:len(b)-suffixLen], prefixLen, prefixLen)
+	for k := 0; k < suffixLen; k++ {
+		ops = append(ops, diffOperation{kind: ' ', aIndex: len(a) - suffixLen + k, bIndex: len(b) - suffixLen + k})
+	}
+	return
+}
+
+// lcsLengthsForward return LCS lengths of a against each prefix b[:j].
+func lcsLengthsForward(a, b []string) []int32 {
+	prev := make([]int32, len(b)+1)
+	curr := make([]int32, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else if prev[j+1] >= curr[j] {
+				curr[j+1] = prev[j+1]
+			} else {
+				curr[j+1] = curr[j]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// lcsLengthsBackward return LCS lengths of a against each suffix b[j:].
+func lcsLengthsBackward(a, b []string) []int32 {
+	prev := make([]int32, len(b)+1)
+	curr := make([]int32, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				curr[j] = prev[j+1] + 1
+			} else if prev[j] >= curr[j+1] {
+				curr[j] = prev[j]
+			} else {
+				curr[j] = curr[j+1]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// diffLinesLinearSpace append edit script from a to b into ops.
+// Line a[0] and b[0] are at aOffset and bOffset of the whole input.
+func diffLinesLinearSpace(ops []diffOperation, a, b []string, aOffset, bOffset int) []diffOperation {
+	if 0 == len(b) {
+		for i := range a {
+			ops = append(ops, diffOperation{kind: '-', aIndex: aOffset + i, bIndex: bOffset})
+		}
+		return ops
+	}
+	if 0 == len(a) {
+		for j := range b {
+			ops = append(ops, diffOperation{kind: '+', aIndex: aOffset, bIndex: bOffset + j})
+		}
+		return ops
+	}
+	if 1 == len(a) {
+		for j := range b {
+			if a[0] != b[j] {
+				continue
+			}
+			for k := 0; k < j; k++ {
+				ops = append(ops, diffOperation{kind: '+', aIndex: aOffset, bIndex: bOffset + k})
+			}
+			ops = append(ops, diffOperation{kind: ' ', aIndex: aOffset, bIndex: bOffset + j})
+			for k := j + 1; k < len(b); k++ {
+				ops = append(ops, diffOperation{kind: '+', aIndex: aOffset + 1, bIndex: bOffset + k})
+			}
+			return ops
+		}
+		ops = append(ops, diffOperation{kind: '-', aIndex: aOffset, bIndex: bOffset})
+		for k := range b {
+			ops = append(ops, diffOperation{kind: '+', aIndex: aOffset + 1, bIndex: bOffset + k})
+		}
+		return ops
+	}
+	aMid := len(a) / 2
+	forward := lcsLengthsForward(a[:aMid], b)
+	backward := lcsLengthsBackward(a[aMid:], b)
+	bMid, bestLength := 0, int32(-1)
+	for j := 0; j <= len(b); j++ {
+		if l := forward[j] + backward[j]; l > bestLength {
+			bMid, bestLength = j, l
+		}
+	}
+	ops = diffLinesLinearSpace(ops, a[:aMid], b[:bMid], aOffset, bOffset)
+	return diffLinesLinearSpace(ops, a[aMid:], b[bMid:], aOffset+aMid, bOffset+bMid)
+}
+
+func makeUnifiedDiffRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(count)
+}
+
+func writeUnifiedDiffHunk(b *strings.Builder, a, bLines []string, ops []diffOperation) {
+	aStart, bStart := ops[0].aIndex, ops[0].bIndex
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			aCount++
+		}
+		if op.kind != '-' {
+			bCount++
+		}
+	}
+	b.WriteString("@@ -" + makeUnifiedDiffRange(aStart, aCount) + " +" + makeUnifiedDiffRange(bStart, bCount) + " @@\n")
+	for _, op := range ops {
+		var line string
+		if op.kind == '+' {
+			line = bLines[op.bIndex]
+		} else {
+			line = a[op.aIndex]
+		}
+		b.WriteByte(op.kind)
+		b.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// makeUnifiedDiff return unified diff from text a to text b, or empty string if both are the same.
+func makeUnifiedDiff(aName, bName, aText, bText string) string {
+	a := splitDiffLines(aText)
+	bLines := splitDiffLines(bText)
+	ops := diffLines(a, bLines)
+	var changeIndexes []int
+	for idx, op := range ops {
+		if op.kind != ' ' {
+			changeIndexes = append(changeIndexes, idx)
+		}
+	}
+	if len(changeIndexes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("--- " + aName + "\n+++ " + bName + "\n")
+	hunkBegin := -1
+	hunkEnd := -1
+	for _, idx := range changeIndexes {
+		begin := idx - unifiedDiffContextLines
+		if begin < 0 {
+			begin = 0
+		}
+		end := idx + unifiedDiffContextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		if (hunkBegin >= 0) && (begin <= hunkEnd) {
+			hunkEnd = end
+			continue
+		}
+		if hunkBegin >= 0 {
+			writeUnifiedDiffHunk(&b, a, bLines, ops[hunkBegin:hunkEnd])
+		}
+		hunkBegin, hunkEnd = begin, end
+	}
+	writeUnifiedDiffHunk(&b, a, bLines, ops[hunkBegin:hunkEnd])
+	return b.String()
+}
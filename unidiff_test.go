@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMakeUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name   string
+		aText  string
+		bText  string
+		result string
+	}{
+		{"same", "l1\nl2\n", "l1\nl2\n", ""},
+		{"insert-at-start", "l1\nl2\nl3\nl4\nl5\n", "l0\nl1\nl2\nl3\nl4\nl5\n",
+			"@@ -1,3 +1,4 @@\n+l0\n l1\n l2\n l3\n"},
+		{"delete-at-end", "l1\nl2\nl3\nl4\nl5\n", "l1\nl2\nl3\nl4\n",
+			"@@ -2,4 +2,3 @@\n l2\n l3\n l4\n-l5\n"},
+		{"insert-into-empty", "", "x\n",
+			"@@ -0,0 +1 @@\n+x\n"},
+		{"delete-all", "a\nb\n", "",
+			"@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"add-newline-at-end", "l1\nl2\nl3", "l1\nl2\nl3\n",
+			"@@ -1,3 +1,3 @@\n l1\n l2\n-l3\n\\ No newline at end of file\n+l3\n"},
+		{"remove-newline-at-end", "a\nb\n", "a\nB",
+			"@@ -1,2 +1,2 @@\n a\n-b\n+B\n\\ No newline at end of file\n"},
+		{"merged-hunk", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "1\nX\n3\n4\n5\n6\n7\n8\nY\n10\n",
+			"@@ -1,10 +1,10 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+Y\n 10\n"},
+		{"separate-hunks", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n",
+			"@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+Y\n 12\n"},
+	}
+	for _, tc := range testCases {
+		expect := tc.result
+		if "" != expect {
+			expect = "--- a\n+++ b\n" + expect
+		}
+		if result := makeUnifiedDiff("a", "b", tc.aText, tc.bText); result != expect {
+			t.Errorf("%s: expect diff\n%s\nbut have\n%s", tc.name, expect, result)
+		}
+	}
+}